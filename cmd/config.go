@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show, validate and change configuration",
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
+
+// Returns every problem with the values in cfg.
+func getConfigProblems(cfg *config.Config) []error {
+	problems := []error{}
+	if err := cfg.Validate(); err != nil {
+		if joinedErr, ok := err.(interface{ Unwrap() []error }); ok {
+			problems = append(problems, joinedErr.Unwrap()...)
+		} else {
+			problems = append(problems, err)
+		}
+	}
+	if err := checkSchedulerName(cfg.Scheduler); err != nil {
+		problems = append(problems, err)
+	}
+	return problems
+}
+
+func checkSchedulerName(name string) error {
+	if !slices.Contains(scheduler.SchedulerNames, name) {
+		return fmt.Errorf("scheduler must be one of %s, but is %q", strings.Join(scheduler.SchedulerNames, ", "), name)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configSetFlags = struct {
+	DeckName string
+}{}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+	configSetCmd.Flags().StringVarP(&configSetFlags.DeckName, "deck", "d", "", "set the value in the config of this deck")
+}
+
+var configSetCmd = &cobra.Command{
+	Use:         "set <key> <value>",
+	Short:       "Set a config value",
+	Annotations: writesDecks,
+	Long: `Sets a config value in the config file in the data directory, creating
+the file if it does not exist. If --deck is passed, the value is set in
+the config of that deck instead. Keys are dot-separated paths, as shown
+by "clsr config show". Values are parsed as YAML, so lists can be set
+like this:
+
+    clsr config set leitner.box_intervals "[24, 48, 96]"
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		value, err := config.ParseValue(args[1])
+		if err != nil {
+			return err
+		}
+		if key == "scheduler" {
+			name, _ := value.(string)
+			if err := checkSchedulerName(name); err != nil {
+				return err
+			}
+		}
+
+		if !cmd.Flags().Changed("deck") {
+			configFilePath := config.GetDataDirectoryFilePath(deckDirectory)
+			if err := config.SetFileValue(deckDirectory, key, value); err != nil {
+				return fmt.Errorf("failed to set value in %q: %w", configFilePath, err)
+			}
+			return nil
+		}
+
+		// set the value in the deck's config
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		deck, err := deckSource.ReadDeck(configSetFlags.DeckName)
+		if err != nil {
+			return fmt.Errorf("failed to read deck %q: %w", configSetFlags.DeckName, err)
+		}
+		overrides, err := config.SetOverride(deck.Config, key, value)
+		if err != nil {
+			return err
+		}
+		if problems := getConfigProblems(cfg.WithOverrides(overrides)); len(problems) > 0 {
+			return fmt.Errorf("invalid config for deck %q:\n%w", deck.Name, errors.Join(problems...))
+		}
+		deck.Config = overrides
+		if err := deckSource.WriteDeck(deck); err != nil {
+			return fmt.Errorf("failed to write deck %q: %w", deck.Name, err)
+		}
+		return nil
+	},
+}
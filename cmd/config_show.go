@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configShowFlags = struct {
+	DeckName string
+}{}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().StringVarP(&configShowFlags.DeckName, "deck", "d", "", "show the config that applies to the cards of this deck")
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective config and where each value came from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, sources, err := config.LoadWithSources(deckDirectory)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// apply the deck's overrides if necessary
+		if cmd.Flags().Changed("deck") {
+			deckSource, err := getDeckSource(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to instantiate deck source: %w", err)
+			}
+			deck, err := deckSource.ReadDeck(configShowFlags.DeckName)
+			if err != nil {
+				return fmt.Errorf("failed to read deck %q: %w", configShowFlags.DeckName, err)
+			}
+			keys, err := config.OverrideKeys(deck.Config)
+			if err != nil {
+				return fmt.Errorf("failed to get keys of deck config: %w", err)
+			}
+			for _, key := range keys {
+				sources[key] = fmt.Sprintf("deck %s", deck.Name)
+			}
+			cfg = cfg.WithOverrides(deck.Config)
+		}
+
+		entries, err := cfg.Entries()
+		if err != nil {
+			return fmt.Errorf("failed to get config values: %w", err)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
+		if _, err := fmt.Fprintln(writer, "Key\tValue\tSource"); err != nil {
+			return fmt.Errorf("failed to write header row: %w", err)
+		}
+		for _, entry := range entries {
+			_, err := fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.Key, entry.Value, sources.Get(entry.Key))
+			if err != nil {
+				return fmt.Errorf("failed to write row for %q: %w", entry.Key, err)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("failed to flush writer: %w", err)
+		}
+		return nil
+	},
+}
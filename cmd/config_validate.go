@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config and the config of each deck for problems",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := config.LoadWithSources(deckDirectory)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		problems := getConfigProblems(cfg)
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		decks, err := utils.GetDecks(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		for _, deck := range decks {
+			if deck.Config == nil {
+				continue
+			}
+			deckProblems := getConfigProblems(cfg.WithOverrides(deck.Config))
+			for _, problem := range deckProblems {
+				fmt.Printf("deck %s: %s\n", deck.Name, problem)
+			}
+			problems = append(problems, deckProblems...)
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("found %d problem(s) with config", len(problems))
+		}
+		fmt.Println("config is valid")
+		return nil
+	},
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -19,12 +18,17 @@ func init() {
 }
 
 var createCardCmd = &cobra.Command{
-	Use:   "card",
-	Short: "Create card",
-	Args:  cobra.NoArgs,
+	Use:         "card",
+	Short:       "Create card",
+	Annotations: writesDecks,
+	Args:        cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		deckName := createCardFlags.DeckName
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
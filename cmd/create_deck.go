@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -13,12 +12,17 @@ func init() {
 }
 
 var createDeckCmd = &cobra.Command{
-	Use:   "deck <name>",
-	Short: "Create deck",
-	Args:  cobra.ExactArgs(1),
+	Use:         "deck <name>",
+	Short:       "Create deck",
+	Annotations: writesDecks,
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		deckName := args[0]
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/adamkpickering/clsr/internal/utils"
 	"github.com/spf13/cobra"
@@ -14,12 +13,17 @@ func init() {
 }
 
 var editCardCmd = &cobra.Command{
-	Use:   "card <card_id>",
-	Short: "Edit card",
-	Args:  cobra.ExactArgs(1),
+	Use:         "card <card_id>",
+	Short:       "Edit card",
+	Annotations: writesDecks,
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// search for the card in all decks
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/adamkpickering/clsr/internal/stats"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var forecastFlags = struct {
+	Days      int
+	Weekly    bool
+	DeckNames []string
+}{}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+	forecastCmd.Flags().IntVarP(&forecastFlags.Days, "days", "n", 14, "number of days to forecast")
+	forecastCmd.Flags().BoolVarP(&forecastFlags.Weekly, "weekly", "w", false, "show the number of cards due each week instead of each day")
+	forecastCmd.Flags().StringSliceVarP(&forecastFlags.DeckNames, "decks", "d", []string{}, "only forecast cards from these decks")
+	addSchedulerFlag(forecastCmd)
+}
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Show how many cards come due in the coming days",
+	Long: `Show how many active cards come due on each of the coming days,
+for each active deck. Cards that are due now are counted on the
+first day. Unlike "clsr simulate", this does not take into account
+the reviews that will happen in the meantime, so it only counts
+each card once.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if forecastFlags.Days < 1 {
+			return fmt.Errorf("--days must be at least 1")
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		clock, err := getClock()
+		if err != nil {
+			return err
+		}
+		allDecks, err := utils.GetDecks(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		scheduler, err := getScheduler(cfg, clock, allDecks)
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler: %w", err)
+		}
+		selectedDecks, err := utils.SelectDecks(allDecks, forecastFlags.DeckNames...)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		decks := make([]*models.Deck, 0, len(selectedDecks))
+		for _, deck := range selectedDecks {
+			if deck.Active {
+				decks = append(decks, deck)
+			}
+		}
+
+		periodLength := 1
+		if forecastFlags.Weekly {
+			periodLength = 7
+		}
+		today := utils.StartOfDay(clock.Now(), cfg.DayStartHour)
+		counts, err := getForecastCounts(decks, scheduler, clock.Now(), cfg.DayStartHour, forecastFlags.Days, periodLength)
+		if err != nil {
+			return err
+		}
+		return printForecastTable(decks, counts, today, periodLength)
+	},
+}
+
+// Returns the number of active cards that come due in each period of
+// periodLength days, for each deck, starting with the current day. The
+// first index is the index of the period, and the second is the index
+// of the deck in decks. Cards that come due after days days are not
+// counted.
+func getForecastCounts(decks []*models.Deck, scheduler scheduler.Scheduler, now time.Time, dayStartHour uint, days, periodLength int) ([][]int, error) {
+	periodCount := (days + periodLength - 1) / periodLength
+	counts := make([][]int, periodCount)
+	for i := range counts {
+		counts[i] = make([]int, len(decks))
+	}
+	for deckIndex, deck := range decks {
+		forecast, err := stats.GetDueForecast(deck.Cards, scheduler, now, dayStartHour, days)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get forecast for deck %q: %w", deck.Name, err)
+		}
+		for dayIndex, count := range forecast {
+			counts[dayIndex/periodLength][deckIndex] += count
+		}
+	}
+	return counts, nil
+}
+
+func printForecastTable(decks []*models.Deck, counts [][]int, today time.Time, periodLength int) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
+	header := []string{"Date"}
+	if periodLength > 1 {
+		header[0] = "Week Starting"
+	}
+	for _, deck := range decks {
+		header = append(header, deck.Name)
+	}
+	header = append(header, "Total")
+	if _, err := fmt.Fprintln(writer, strings.Join(header, "\t")); err != nil {
+		return fmt.Errorf("failed to write header row: %w", err)
+	}
+	for i, deckCounts := range counts {
+		date := today.AddDate(0, 0, i*periodLength).Format("2006-01-02")
+		row := []string{date}
+		total := 0
+		for _, count := range deckCounts {
+			row = append(row, fmt.Sprint(count))
+			total += count
+		}
+		row = append(row, fmt.Sprint(total))
+		if _, err := fmt.Fprintln(writer, strings.Join(row, "\t")); err != nil {
+			return fmt.Errorf("failed to write row for %s: %w", date, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	return nil
+}
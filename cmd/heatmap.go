@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adamkpickering/clsr/internal/stats"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/adamkpickering/clsr/internal/views"
+	"github.com/spf13/cobra"
+)
+
+var heatmapFlags = struct {
+	Weeks     int
+	NoColor   bool
+	DeckNames []string
+}{}
+
+func init() {
+	rootCmd.AddCommand(heatmapCmd)
+	heatmapCmd.Flags().IntVarP(&heatmapFlags.Weeks, "weeks", "w", 52, "number of weeks to show")
+	heatmapCmd.Flags().BoolVar(&heatmapFlags.NoColor, "no-color", false, "draw the heatmap without colours (also set by the NO_COLOR environment variable)")
+	heatmapCmd.Flags().StringSliceVarP(&heatmapFlags.DeckNames, "decks", "d", []string{}, "only include reviews from these decks")
+}
+
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap",
+	Short: "Show a calendar of review activity",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if heatmapFlags.Weeks < 1 {
+			return fmt.Errorf("--weeks must be at least 1")
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		clock, err := getClock()
+		if err != nil {
+			return err
+		}
+		cards, err := utils.GetCards(deckSource, heatmapFlags.DeckNames...)
+		if err != nil {
+			return fmt.Errorf("failed to get cards: %w", err)
+		}
+
+		dailyCounts := stats.GetDailyReviewCounts(cards, cfg.DayStartHour)
+		heatmap := views.NewHeatmap(dailyCounts, clock.Now(), heatmapFlags.Weeks, cfg.DayStartHour)
+		color := !heatmapFlags.NoColor && os.Getenv("NO_COLOR") == ""
+		fmt.Print(heatmap.Render(color))
+
+		total := 0
+		for _, weekCounts := range heatmap.Counts {
+			for _, count := range weekCounts {
+				total += max(count, 0)
+			}
+		}
+		currentStreak, longestStreak := stats.GetStreaks(dailyCounts, clock.Now(), cfg.DayStartHour)
+		fmt.Printf("\n%d reviews in the last %d weeks. Current streak: %d days. Longest streak: %d days.\n",
+			total, heatmapFlags.Weeks, currentStreak, longestStreak)
+		return nil
+	},
+}
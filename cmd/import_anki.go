@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/spf13/cobra"
 	"os"
@@ -22,8 +21,9 @@ type ankiExportFileHeaders struct {
 }
 
 var importAnkiCmd = &cobra.Command{
-	Use:   "anki <path>",
-	Short: "Import from Anki",
+	Use:         "anki <path>",
+	Short:       "Import from Anki",
+	Annotations: writesDecks,
 	Long: `Imports decks from Anki. To export decks from Anki, click on
 File > Export. Ensure you have the following things set:
 
@@ -70,7 +70,11 @@ to this command and clsr will do the rest.
 		}
 
 		// get deck source
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
@@ -7,8 +7,6 @@ import (
 	"text/tabwriter"
 	"time"
 
-	"github.com/adamkpickering/clsr/internal/config"
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/adamkpickering/clsr/internal/scheduler"
 	"github.com/adamkpickering/clsr/internal/utils"
@@ -16,13 +14,17 @@ import (
 )
 
 type CardRow struct {
-	ID           string
-	Deck         string
-	Active       bool
-	ReviewCount  int
-	LastReviewed string
-	NextReview   string
-	Question     string
+	ID           string `json:"id" yaml:"id"`
+	Deck         string `json:"deck" yaml:"deck"`
+	Active       bool   `json:"active" yaml:"active"`
+	ReviewCount  int    `json:"review_count" yaml:"review_count"`
+	LastReviewed string `json:"last_reviewed" yaml:"last_reviewed"`
+	NextReview   string `json:"next_review" yaml:"next_review"`
+	Question     string `json:"question" yaml:"question"`
+	// The time of the last review, or nil if the card has not
+	// been reviewed.
+	LastReviewedTime *time.Time `json:"last_reviewed_time" yaml:"last_reviewed_time"`
+	NextReviewTime   time.Time  `json:"next_review_time" yaml:"next_review_time"`
 }
 
 var listCardFlags = struct {
@@ -32,6 +34,9 @@ var listCardFlags = struct {
 func init() {
 	listCmd.AddCommand(listCardCmd)
 	listCardCmd.Flags().StringSliceVarP(&listCardFlags.DeckNames, "decks", "d", []string{}, "only list cards from these decks")
+	addSchedulerFlag(listCardCmd)
+	addOutputFlags(listCardCmd)
+	addQueryFlag(listCardCmd)
 }
 
 var listCardCmd = &cobra.Command{
@@ -39,30 +44,59 @@ var listCardCmd = &cobra.Command{
 	Short: "List cards",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFlags(cmd); err != nil {
+			return err
+		}
+		if _, err := getQuery(cmd); err != nil {
+			return err
+		}
 		deckName := listCardFlags.DeckNames
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
-		scheduler := scheduler.NewTwoReviewScheduler(config.DefaultConfig)
+		clock, err := getClock()
+		if err != nil {
+			return err
+		}
+		allDecks, err := utils.GetDecks(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		scheduler, err := getScheduler(cfg, clock, allDecks)
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler: %w", err)
+		}
 
 		// get a list of cards
-		cards, err := utils.GetCards(deckSource, deckName...)
+		decks, err := utils.SelectDecks(allDecks, deckName...)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		cards := []*models.Card{}
+		for _, deck := range decks {
+			cards = append(cards, deck.Cards...)
+		}
+		cards, err = filterCardsByQuery(cmd, cards, scheduler, cfg)
 		if err != nil {
-			return fmt.Errorf("failed to get cards: %w", err)
+			return fmt.Errorf("failed to filter cards: %w", err)
 		}
 
 		// convert cards to CardRows
-		var cardRows []CardRow
+		cardRows := []CardRow{}
 		for _, card := range cards {
-			cardRow, err := cardToCardRow(card, scheduler)
+			cardRow, err := cardToCardRow(card, scheduler, cfg.DayStartHour, clock.Now())
 			if err != nil {
 				return fmt.Errorf("failed to convert Card %q to CardRow: %w", card.ID, err)
 			}
 			cardRows = append(cardRows, cardRow)
 		}
 
-		return printCardTable(cardRows)
+		return printRows(cardRows, printCardTable)
 	},
 }
 
@@ -92,7 +126,7 @@ func printCardTable(cardRows []CardRow) error {
 	return nil
 }
 
-func cardToCardRow(card *models.Card, scheduler scheduler.Scheduler) (CardRow, error) {
+func cardToCardRow(card *models.Card, scheduler scheduler.Scheduler, dayStartHour uint, now time.Time) (CardRow, error) {
 	row := CardRow{
 		ID:          card.ID,
 		Deck:        card.Deck,
@@ -110,17 +144,20 @@ func cardToCardRow(card *models.Card, scheduler scheduler.Scheduler) (CardRow, e
 	if err != nil {
 		return CardRow{}, fmt.Errorf("failed during check whether card is due: %w", err)
 	}
+	row.NextReviewTime = nextReview
 	if due {
 		row.NextReview = "due"
 	} else {
-		row.NextReview = utils.GetReadableTimeDifference(time.Now(), nextReview)
+		row.NextReview = utils.GetReadableTimeDifference(now, nextReview, dayStartHour)
 	}
 
 	// deal with LastReviewed
 	if len(card.Reviews) == 0 {
 		row.LastReviewed = "never"
 	} else {
-		readableTimeDifference := utils.GetReadableTimeDifference(card.Reviews[0].Datetime, time.Now())
+		lastReviewedTime := card.Reviews[0].Datetime
+		row.LastReviewedTime = &lastReviewedTime
+		readableTimeDifference := utils.GetReadableTimeDifference(card.Reviews[0].Datetime, now, dayStartHour)
 		lastReviewed := fmt.Sprintf("%s ago", readableTimeDifference)
 		row.LastReviewed = lastReviewed
 	}
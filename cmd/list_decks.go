@@ -5,14 +5,21 @@ import (
 	"os"
 	"text/tabwriter"
 
-	"github.com/adamkpickering/clsr/internal/config"
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/adamkpickering/clsr/internal/scheduler"
 	"github.com/adamkpickering/clsr/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+type DeckRow struct {
+	Name          string `json:"name" yaml:"name"`
+	CardsDue      int    `json:"cards_due" yaml:"cards_due"`
+	ActiveCards   int    `json:"active_cards" yaml:"active_cards"`
+	InactiveCards int    `json:"inactive_cards" yaml:"inactive_cards"`
+	TotalCards    int    `json:"total_cards" yaml:"total_cards"`
+	Active        bool   `json:"active" yaml:"active"`
+}
+
 var listDeckFlags = struct {
 	All bool
 }{}
@@ -20,6 +27,8 @@ var listDeckFlags = struct {
 func init() {
 	listCmd.AddCommand(listDeckCmd)
 	listDeckCmd.Flags().BoolVarP(&listDeckFlags.All, "all", "a", false, "list all decks, not just active ones")
+	addSchedulerFlag(listDeckCmd)
+	addOutputFlags(listDeckCmd)
 }
 
 var listDeckCmd = &cobra.Command{
@@ -27,7 +36,14 @@ var listDeckCmd = &cobra.Command{
 	Short: "List decks",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		if err := checkOutputFlags(cmd); err != nil {
+			return err
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
@@ -46,33 +62,59 @@ var listDeckCmd = &cobra.Command{
 				}
 			}
 		}
-		return printDeckTable(decks)
+		clock, err := getClock()
+		if err != nil {
+			return err
+		}
+		scheduler, err := getScheduler(cfg, clock, allDecks)
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler: %w", err)
+		}
+		deckRows := make([]DeckRow, 0, len(decks))
+		for _, deck := range decks {
+			deckRow, err := deckToDeckRow(deck, scheduler)
+			if err != nil {
+				return fmt.Errorf("failed to convert Deck %q to DeckRow: %w", deck.Name, err)
+			}
+			deckRows = append(deckRows, deckRow)
+		}
+		return printRows(deckRows, printDeckTable)
 	},
 }
 
-func printDeckTable(decks []*models.Deck) error {
-	scheduler := scheduler.NewTwoReviewScheduler(config.DefaultConfig)
+func deckToDeckRow(deck *models.Deck, scheduler scheduler.Scheduler) (DeckRow, error) {
+	dueCount, err := countCardsDue(deck, scheduler)
+	if err != nil {
+		return DeckRow{}, fmt.Errorf("failed to count due cards: %w", err)
+	}
+	activeCount, inactiveCount := countActiveCards(deck)
+	return DeckRow{
+		Name:          deck.Name,
+		CardsDue:      dueCount,
+		ActiveCards:   activeCount,
+		InactiveCards: inactiveCount,
+		TotalCards:    len(deck.Cards),
+		Active:        deck.Active,
+	}, nil
+}
+
+func printDeckTable(deckRows []DeckRow) error {
 	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
 	_, err := fmt.Fprintln(writer, "Deck\tCards Due\tActive Cards\tInactive Cards\tTotal Cards\tActive")
 	if err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
-	for _, deck := range decks {
-		dueCount, err := countCardsDue(deck, scheduler)
-		if err != nil {
-			return fmt.Errorf("failed to count due cards: %w", err)
-		}
-		activeCount, inactiveCount := countActiveCards(deck)
+	for _, deckRow := range deckRows {
 		_, err = fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d\t%t\n",
-			deck.Name,
-			dueCount,
-			activeCount,
-			inactiveCount,
-			len(deck.Cards),
-			deck.Active,
+			deckRow.Name,
+			deckRow.CardsDue,
+			deckRow.ActiveCards,
+			deckRow.InactiveCards,
+			deckRow.TotalCards,
+			deckRow.Active,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to write row for deck %q: %w", deck.Name, err)
+			return fmt.Errorf("failed to write row for deck %q: %w", deckRow.Name, err)
 		}
 	}
 	if err := writer.Flush(); err != nil {
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+type LeechRow struct {
+	ID       string `json:"id" yaml:"id"`
+	Deck     string `json:"deck" yaml:"deck"`
+	Active   bool   `json:"active" yaml:"active"`
+	Lapses   int    `json:"lapses" yaml:"lapses"`
+	Tagged   bool   `json:"tagged" yaml:"tagged"`
+	Question string `json:"question" yaml:"question"`
+}
+
+var listLeechFlags = struct {
+	DeckNames []string
+}{}
+
+func init() {
+	listCmd.AddCommand(listLeechCmd)
+	listLeechCmd.Flags().StringSliceVarP(&listLeechFlags.DeckNames, "decks", "d", []string{}, "only list leeches from these decks")
+	addOutputFlags(listLeechCmd)
+}
+
+var listLeechCmd = &cobra.Command{
+	Use:   "leeches",
+	Short: "List cards that are failed often",
+	Long: `List cards that have been failed at least leech.threshold times, or
+that have been tagged as leeches. Cards are listed in order of how
+many times they have been failed, most first.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFlags(cmd); err != nil {
+			return err
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		cards, err := utils.GetCards(deckSource, listLeechFlags.DeckNames...)
+		if err != nil {
+			return fmt.Errorf("failed to get cards: %w", err)
+		}
+		leechRows := getLeechRows(cards, cfg.Leech.Threshold)
+		return printRows(leechRows, printLeechTable)
+	},
+}
+
+// Returns a LeechRow for each card in cards that is a leech, sorted
+// by lapse count in descending order.
+func getLeechRows(cards []*models.Card, threshold uint) []LeechRow {
+	leechRows := []LeechRow{}
+	for _, card := range cards {
+		tagged := card.HasTag(models.LeechTag)
+		if !tagged && !card.IsLeech(threshold) {
+			continue
+		}
+		leechRows = append(leechRows, LeechRow{
+			ID:       card.ID,
+			Deck:     card.Deck,
+			Active:   card.Active,
+			Lapses:   card.LapseCount(),
+			Tagged:   tagged,
+			Question: truncateQuestion(card.Question),
+		})
+	}
+	sort.SliceStable(leechRows, func(i, j int) bool {
+		return leechRows[i].Lapses > leechRows[j].Lapses
+	})
+	return leechRows
+}
+
+func printLeechTable(leechRows []LeechRow) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
+	_, err := fmt.Fprintln(writer, "ID\tDeck\tActive\tLapses\tTagged\tQuestion")
+	if err != nil {
+		return fmt.Errorf("failed to write header row: %w", err)
+	}
+	for _, leechRow := range leechRows {
+		_, err = fmt.Fprintf(writer, "%s\t%s\t%t\t%d\t%t\t%s\n",
+			leechRow.ID,
+			leechRow.Deck,
+			leechRow.Active,
+			leechRow.Lapses,
+			leechRow.Tagged,
+			leechRow.Question,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write row for card %q: %w", leechRow.ID, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	return nil
+}
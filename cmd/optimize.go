@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var optimizeFlags = struct {
+	Iterations int
+	Write      bool
+}{}
+
+func init() {
+	rootCmd.AddCommand(optimizeCmd)
+	optimizeCmd.Flags().IntVarP(&optimizeFlags.Iterations, "iterations", "i", 200, "number of optimization steps to take")
+	optimizeCmd.Flags().BoolVarP(&optimizeFlags.Write, "write", "w", false, "write the fitted weights to the config file in the data directory")
+}
+
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize",
+	Short: "Fit FSRS scheduler weights to your review history",
+	Long: `Fits the weights of the FSRS scheduler to the reviews of every card
+in every deck, by minimizing the log loss of the predicted probability
+of recalling each card against whether each review was actually passed
+or failed. Prints the fitted weights along with the loss before and
+after fitting. Fitting starts from the weights in the current config.
+
+If --write is passed, the fitted weights are written to the config file
+in the data directory, and are used from then on by the fsrs scheduler.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if optimizeFlags.Iterations < 1 {
+			return fmt.Errorf("iterations must be at least 1")
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		cards, err := utils.GetCards(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get cards: %w", err)
+		}
+
+		initialWeights := cfg.FSRS.Weights
+		initialLoss, err := scheduler.GetFSRSLogLoss(initialWeights, cards)
+		if err != nil {
+			return fmt.Errorf("failed to compute initial loss: %w", err)
+		}
+		weights, err := scheduler.OptimizeFSRSWeights(initialWeights, cards, optimizeFlags.Iterations)
+		if err != nil {
+			return fmt.Errorf("failed to optimize weights: %w", err)
+		}
+		finalLoss, err := scheduler.GetFSRSLogLoss(weights, cards)
+		if err != nil {
+			return fmt.Errorf("failed to compute final loss: %w", err)
+		}
+
+		fmt.Printf("Loss before: %.4f\n", initialLoss)
+		fmt.Printf("Loss after:  %.4f\n", finalLoss)
+		fmt.Printf("Weights:     %s\n", formatWeights(weights))
+
+		if optimizeFlags.Write {
+			configFilePath := config.GetDataDirectoryFilePath(deckDirectory)
+			if err := config.SetFileValue(deckDirectory, "fsrs.weights", weights); err != nil {
+				return fmt.Errorf("failed to write weights to config file: %w", err)
+			}
+			fmt.Printf("Wrote weights to %s\n", configFilePath)
+		}
+		return nil
+	},
+}
+
+func formatWeights(weights []float64) string {
+	formattedWeights := make([]string, 0, len(weights))
+	for _, weight := range weights {
+		formattedWeights = append(formattedWeights, strconv.FormatFloat(weight, 'f', 4, 64))
+	}
+	return strings.Join(formattedWeights, ", ")
+}
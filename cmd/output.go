@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// The formats that may be passed to --output.
+var outputFormats = []string{"table", "json", "yaml", "csv"}
+
+var outputFormat string
+var outputTemplate string
+
+// Functions that may be used in templates passed to --format.
+var outputTemplateFuncs = template.FuncMap{
+	"json": func(value any) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Adds the --output and --format flags to a command that lists rows.
+func addOutputFlags(cmd *cobra.Command) {
+	usage := fmt.Sprintf("output format (one of %s)", strings.Join(outputFormats, ", "))
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", usage)
+	cmd.Flags().StringVar(&outputTemplate, "format", "", `print each row using a Go template, e.g. "{{.ID}} {{.Deck}}"`)
+}
+
+// Prints rows to stdout in the format passed to --output, or using
+// the template passed to --format. The "table" format is printed by
+// printTable. Other formats include every field of the rows, named
+// by their json struct tags.
+func printRows[T any](rows []T, printTable func([]T) error) error {
+	if outputTemplate != "" {
+		return printTemplate(rows)
+	}
+	switch outputFormat {
+	case "table":
+		return printTable(rows)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(rows); err != nil {
+			return fmt.Errorf("failed to encode rows as JSON: %w", err)
+		}
+		return nil
+	case "yaml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(rows); err != nil {
+			return fmt.Errorf("failed to encode rows as YAML: %w", err)
+		}
+		return encoder.Close()
+	default:
+		return printCSV(rows)
+	}
+}
+
+// Checks that the values passed to --output and --format are valid.
+// This should be called before doing any work, so that invalid values
+// are caught early.
+func checkOutputFlags(cmd *cobra.Command) error {
+	if !slices.Contains(outputFormats, outputFormat) {
+		return fmt.Errorf("invalid output format %q: must be one of %s", outputFormat, strings.Join(outputFormats, ", "))
+	}
+	if outputTemplate == "" {
+		return nil
+	}
+	if cmd.Flags().Changed("output") {
+		return errors.New("--output and --format may not be used together")
+	}
+	if _, err := parseOutputTemplate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseOutputTemplate() (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(outputTemplateFuncs).Parse(outputTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Prints each of rows on its own line using the template passed
+// to --format.
+func printTemplate[T any](rows []T) error {
+	tmpl, err := parseOutputTemplate()
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(os.Stdout)
+	for _, row := range rows {
+		if err := tmpl.Execute(writer, row); err != nil {
+			return fmt.Errorf("failed to execute --format template: %w", err)
+		}
+		if err := writer.WriteByte('\n'); err != nil {
+			return fmt.Errorf("failed to write newline: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	return nil
+}
+
+// Prints rows as CSV with a header row. The columns are the fields of
+// T, named by their json struct tags.
+func printCSV[T any](rows []T) error {
+	writer := csv.NewWriter(os.Stdout)
+	rowType := reflect.TypeOf((*T)(nil)).Elem()
+	header := make([]string, 0, rowType.NumField())
+	for i := 0; i < rowType.NumField(); i++ {
+		name, _, _ := strings.Cut(rowType.Field(i).Tag.Get("json"), ",")
+		header = append(header, name)
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write header row: %w", err)
+	}
+	for _, row := range rows {
+		value := reflect.ValueOf(row)
+		record := make([]string, 0, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			record = append(record, formatCSVValue(value.Field(i).Interface()))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	return nil
+}
+
+func formatCSVValue(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/query"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/spf13/cobra"
+)
+
+var queryString string
+
+// Adds the --query flag to a command that selects cards.
+func addQueryFlag(cmd *cobra.Command) {
+	usage := `only select cards that match this query, e.g. 'deck:french is:due -is:inactive lapses>=3 "question text"'`
+	cmd.Flags().StringVarP(&queryString, "query", "q", "", usage)
+}
+
+// Parses the value passed to --query. Returns nil if --query was
+// not passed. An empty query is an error rather than matching every
+// card, since it usually comes from a shell variable that was not set.
+func getQuery(cmd *cobra.Command) (*query.Query, error) {
+	if !cmd.Flags().Changed("query") {
+		return nil, nil
+	}
+	if strings.TrimSpace(queryString) == "" {
+		return nil, errors.New("invalid query: query must not be empty")
+	}
+	parsedQuery, err := query.Parse(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	return parsedQuery, nil
+}
+
+// Returns the cards in cards that match the query passed to --query,
+// or all of cards if no query was passed.
+func filterCardsByQuery(cmd *cobra.Command, cards []*models.Card, scheduler scheduler.Scheduler, cfg *config.Config) ([]*models.Card, error) {
+	parsedQuery, err := getQuery(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if parsedQuery == nil {
+		return cards, nil
+	}
+	context := query.Context{
+		Scheduler:      scheduler,
+		LeechThreshold: cfg.Leech.Threshold,
+	}
+	return parsedQuery.Filter(cards, context)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGetQuery(t *testing.T) {
+	newCommand := func(t *testing.T, args ...string) *cobra.Command {
+		t.Helper()
+		cmd := &cobra.Command{}
+		addQueryFlag(cmd)
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("failed to parse flags: %s", err)
+		}
+		return cmd
+	}
+
+	t.Run("NotPassed", func(t *testing.T) {
+		parsedQuery, err := getQuery(newCommand(t))
+		if err != nil {
+			t.Fatalf("failed to get query: %s", err)
+		}
+		if parsedQuery != nil {
+			t.Errorf("expected no query")
+		}
+	})
+
+	t.Run("Passed", func(t *testing.T) {
+		parsedQuery, err := getQuery(newCommand(t, "--query", "is:due"))
+		if err != nil {
+			t.Fatalf("failed to get query: %s", err)
+		}
+		if parsedQuery == nil {
+			t.Errorf("expected a query")
+		}
+	})
+
+	t.Run("RejectsEmpty", func(t *testing.T) {
+		for _, value := range []string{"", "  \t"} {
+			if _, err := getQuery(newCommand(t, "--query", value)); err == nil {
+				t.Errorf("expected error for query %q", value)
+			}
+		}
+	})
+}
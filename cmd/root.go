@@ -3,11 +3,31 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/spf13/cobra"
 )
 
 var deckDirectory string
+var now string
+
+// The formats that may be passed to --now.
+var nowFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Commands that write to decks have this annotation set to "true".
+// --now cannot be passed to them, so that made-up times are never
+// recorded in decks.
+const writesDecksAnnotation = "writes_decks"
+
+var writesDecks = map[string]string{writesDecksAnnotation: "true"}
 
 var rootCmd = &cobra.Command{
 	Use:   "clsr",
@@ -15,6 +35,12 @@ var rootCmd = &cobra.Command{
 	Long: `clsr allows you to manage and study decks of virtual flash cards.
 It schedules cards according to the principle of spaced repetition
 so that you learn most efficiently.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if now != "" && cmd.Annotations[writesDecksAnnotation] == "true" {
+			return fmt.Errorf("--now cannot be used with %q because it writes to decks", cmd.CommandPath())
+		}
+		return nil
+	},
 }
 
 func init() {
@@ -24,6 +50,27 @@ func init() {
 	}
 	rootCmd.PersistentFlags().StringVarP(&deckDirectory, "data-directory", "p", defaultDeckDirectory, "Path to the data directory")
 	rootCmd.PersistentFlags().Lookup("data-directory").DefValue = ""
+	rootCmd.PersistentFlags().StringVar(&now, "now", "", `Act as if it is this time, e.g. "2024-05-14" or "2024-05-14T09:00" (not allowed for commands that write to decks)`)
+}
+
+// Returns the config that applies to the data directory: the default
+// config with any config files merged over it.
+func getConfig() (*config.Config, error) {
+	return config.Load(deckDirectory)
+}
+
+// Returns a Clock that returns the time passed to --now, or the
+// actual current time if --now was not passed.
+func getClock() (clock.Clock, error) {
+	if now == "" {
+		return clock.RealClock{}, nil
+	}
+	for _, format := range nowFormats {
+		if t, err := time.ParseInLocation(format, now, time.Local); err == nil {
+			return clock.NewFixedClock(t), nil
+		}
+	}
+	return nil, fmt.Errorf("failed to parse --now value %q: must be in one of the formats %q", now, nowFormats)
 }
 
 func Execute() {
@@ -36,3 +83,23 @@ func SetVersionInfo(version string) {
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate("{{ .Version }}\n")
 }
+
+// Returns the DeckSource for the data directory, configured by cfg.
+func getDeckSource(cfg *config.Config) (deck_source.DeckSource, error) {
+	switch cfg.DeckFormat {
+	case config.DeckFormatYAML:
+		deckSource, err := deck_source.NewYAMLFileDeckSource(deckDirectory)
+		if err != nil {
+			return nil, err
+		}
+		deckSource.Backup = cfg.BackupDecks
+		return deckSource, nil
+	default:
+		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		if err != nil {
+			return nil, err
+		}
+		deckSource.Backup = cfg.BackupDecks
+		return deckSource, nil
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/spf13/cobra"
+)
+
+var schedulerName string
+
+// Adds the --scheduler flag to a command that schedules cards.
+func addSchedulerFlag(cmd *cobra.Command) {
+	usage := fmt.Sprintf("scheduler to use for all decks (one of %s)", strings.Join(scheduler.SchedulerNames, ", "))
+	cmd.Flags().StringVar(&schedulerName, "scheduler", "", usage)
+}
+
+// Returns a Scheduler for the cards in decks, which should be all of
+// the decks in the deck source. Each deck is scheduled according to
+// cfg with that deck's overrides applied to it. If the --scheduler
+// flag was passed, it takes precedence over the scheduler set in the
+// config and in any deck's overrides. If load balancing is enabled,
+// the due dates of all active cards in decks are used to balance the
+// load. clock is used to get the current time.
+func getScheduler(cfg *config.Config, clock clock.Clock, decks []*models.Deck) (scheduler.Scheduler, error) {
+	cfg = cfg.Copy()
+	if schedulerName != "" {
+		cfg.Scheduler = schedulerName
+	}
+
+	deckConfigs := map[string]*config.Config{}
+	for _, deck := range decks {
+		if deck.Config == nil {
+			continue
+		}
+		deckConfig := cfg.WithOverrides(deck.Config)
+		if schedulerName != "" {
+			deckConfig.Scheduler = schedulerName
+		}
+		if err := deckConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config for deck %q:\n%w", deck.Name, err)
+		}
+		deckConfigs[deck.Name] = deckConfig
+	}
+
+	deckScheduler, err := scheduler.NewDeckScheduler(cfg, deckConfigs, clock)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LoadBalance.WindowFactor == 0 {
+		return deckScheduler, nil
+	}
+
+	cards := []*models.Card{}
+	for _, deck := range decks {
+		if deck.Active {
+			cards = append(cards, deck.Cards...)
+		}
+	}
+	return scheduler.NewLoadBalancer(deckScheduler, cards, cfg, clock)
+}
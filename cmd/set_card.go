@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/adamkpickering/clsr/internal/utils"
 	"github.com/spf13/cobra"
@@ -11,49 +10,90 @@ import (
 
 func init() {
 	setCmd.AddCommand(setCardCmd)
+	addQueryFlag(setCardCmd)
 }
 
 var setCardCmd = &cobra.Command{
-	Use:   "card <card_id> (active|inactive)",
-	Short: "Set whether a card is active or inactive",
-	Args:  cobra.ExactArgs(2),
+	Use:         "card (<card_id>|--query <query>) (active|inactive)",
+	Short:       "Set whether a card is active or inactive",
+	Annotations: writesDecks,
+	Long: `Set whether a card is active or inactive. If --query is passed
+instead of a card ID, every card that matches the query is set.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("query") {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		adjective := args[len(args)-1]
+		if adjective != "active" && adjective != "inactive" {
+			return fmt.Errorf("invalid adjective %q", adjective)
+		}
+		if _, err := getQuery(cmd); err != nil {
+			return err
+		}
+		active := adjective == "active"
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
-
-		// search all the decks for the card
 		decks, err := utils.GetDecks(deckSource)
 		if err != nil {
 			return fmt.Errorf("failed to get decks: %w", err)
 		}
-		card, deck, err := getCardFromDecks(decks, args[0])
-		if err != nil {
-			return fmt.Errorf("failed to find card %q: %w", args[0], err)
-		}
 
-		// modify the card
-		adjective := args[1]
-		switch adjective {
-		case "active":
-			if !card.Active {
-				card.Active = true
-				card.Modified = true
+		// find the cards to set
+		var cards []*models.Card
+		if cmd.Flags().Changed("query") {
+			clock, err := getClock()
+			if err != nil {
+				return err
 			}
-		case "inactive":
-			if card.Active {
-				card.Active = false
+			scheduler, err := getScheduler(cfg, clock, decks)
+			if err != nil {
+				return fmt.Errorf("failed to get scheduler: %w", err)
+			}
+			for _, deck := range decks {
+				cards = append(cards, deck.Cards...)
+			}
+			cards, err = filterCardsByQuery(cmd, cards, scheduler, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to filter cards: %w", err)
+			}
+		} else {
+			card, _, err := getCardFromDecks(decks, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to find card %q: %w", args[0], err)
+			}
+			cards = []*models.Card{card}
+		}
+
+		// modify the cards
+		modifiedCount := 0
+		for _, card := range cards {
+			if card.Active != active {
+				card.Active = active
 				card.Modified = true
+				modifiedCount += 1
 			}
-		default:
-			return fmt.Errorf("invalid adjective %q", adjective)
 		}
 
-		// write changed card to deck
-		err = deckSource.WriteDeck(deck)
-		if err != nil {
-			return fmt.Errorf("failed to write deck %q: %w", deck.Name, err)
+		// write changed cards to their decks
+		for _, deck := range decks {
+			if !deck.IsModified() {
+				continue
+			}
+			if err := deckSource.WriteDeck(deck); err != nil {
+				return fmt.Errorf("failed to write deck %q: %w", deck.Name, err)
+			}
+		}
+		if cmd.Flags().Changed("query") {
+			fmt.Printf("Set %d of %d matching cards to %s\n", modifiedCount, len(cards), adjective)
 		}
 
 		return nil
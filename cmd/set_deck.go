@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/spf13/cobra"
 )
 
@@ -12,11 +11,16 @@ func init() {
 }
 
 var setDeckCmd = &cobra.Command{
-	Use:   "deck <deck_name> (active|inactive)",
-	Short: "Set whether a deck is active or inactive",
-	Args:  cobra.ExactArgs(2),
+	Use:         "deck <deck_name> (active|inactive)",
+	Short:       "Set whether a deck is active or inactive",
+	Annotations: writesDecks,
+	Args:        cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
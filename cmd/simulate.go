@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/simulation"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// The width in characters of the longest bar printed by --chart.
+const chartWidth = 50
+
+var simulateFlags = struct {
+	Days      int
+	PassRate  float64
+	Seed      int64
+	Chart     bool
+	DeckNames []string
+}{}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+	simulateCmd.Flags().IntVarP(&simulateFlags.Days, "days", "n", 30, "number of days to simulate")
+	simulateCmd.Flags().Float64Var(&simulateFlags.PassRate, "pass-rate", 0, "fraction of reviews that are not failed (default measured from review history)")
+	simulateCmd.Flags().Int64Var(&simulateFlags.Seed, "seed", 1, "seed for deciding which simulated reviews are failed")
+	simulateCmd.Flags().BoolVar(&simulateFlags.Chart, "chart", false, "print a chart instead of a table")
+	simulateCmd.Flags().StringSliceVarP(&simulateFlags.DeckNames, "decks", "d", []string{}, "only simulate cards from these decks")
+	addSchedulerFlag(simulateCmd)
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Project the number of reviews per day",
+	Long: `Simulate studying every day using the current scheduler and config,
+and print the number of reviews on each day. Each simulated review is
+failed at random, so that the given fraction of reviews is passed. If
+--pass-rate is not passed, the fraction of past reviews that were
+passed is used. No changes are made to any deck.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if simulateFlags.Days < 1 {
+			return fmt.Errorf("--days must be at least 1")
+		}
+		if simulateFlags.PassRate < 0 || simulateFlags.PassRate > 1 {
+			return fmt.Errorf("--pass-rate must be between 0 and 1")
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		realClock, err := getClock()
+		if err != nil {
+			return err
+		}
+		simulatedClock := clock.NewFixedClock(realClock.Now())
+		allDecks, err := utils.GetDecks(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		scheduler, err := getScheduler(cfg, simulatedClock, allDecks)
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler: %w", err)
+		}
+
+		// get the active cards of active decks
+		decks, err := utils.SelectDecks(allDecks, simulateFlags.DeckNames...)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		cards := []*models.Card{}
+		for _, deck := range decks {
+			if !deck.Active {
+				continue
+			}
+			for _, card := range deck.Cards {
+				if card.Active {
+					cards = append(cards, card)
+				}
+			}
+		}
+
+		passRate := simulateFlags.PassRate
+		if !cmd.Flags().Changed("pass-rate") {
+			measuredPassRate, ok := simulation.MeasurePassRate(cards)
+			if ok {
+				passRate = measuredPassRate
+				fmt.Printf("Pass rate: %.0f%% (measured from review history)\n\n", passRate*100)
+			} else {
+				passRate = simulation.DefaultPassRate
+				fmt.Printf("Pass rate: %.0f%% (no review history to measure from)\n\n", passRate*100)
+			}
+		}
+
+		options := simulation.Options{
+			Days:         simulateFlags.Days,
+			PassRate:     passRate,
+			Seed:         simulateFlags.Seed,
+			DayStartHour: cfg.DayStartHour,
+		}
+		days, err := simulation.Run(cards, scheduler, simulatedClock, options)
+		if err != nil {
+			return fmt.Errorf("failed to run simulation: %w", err)
+		}
+		if simulateFlags.Chart {
+			printSimulationChart(days)
+			return nil
+		}
+		return printSimulationTable(days)
+	},
+}
+
+func printSimulationTable(days []simulation.Day) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
+	_, err := fmt.Fprintln(writer, "Date\tReviews\tNew Cards\tFailed")
+	if err != nil {
+		return fmt.Errorf("failed to write header row: %w", err)
+	}
+	totalReviews := 0
+	for _, day := range days {
+		_, err = fmt.Fprintf(writer, "%s\t%d\t%d\t%d\n",
+			day.Date.Format("2006-01-02"),
+			day.Reviews,
+			day.NewCards,
+			day.Failed,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write row for %s: %w", day.Date.Format("2006-01-02"), err)
+		}
+		totalReviews += day.Reviews
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	fmt.Printf("\nAverage reviews per day: %.1f\n", float64(totalReviews)/float64(len(days)))
+	return nil
+}
+
+func printSimulationChart(days []simulation.Day) {
+	maxReviews := 0
+	for _, day := range days {
+		maxReviews = max(maxReviews, day.Reviews)
+	}
+	for _, day := range days {
+		barLength := 0
+		if maxReviews > 0 {
+			barLength = day.Reviews * chartWidth / maxReviews
+		}
+		if barLength == 0 && day.Reviews > 0 {
+			barLength = 1
+		}
+		fmt.Printf("%s | %s %d\n", day.Date.Format("2006-01-02"), strings.Repeat("#", barLength), day.Reviews)
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/adamkpickering/clsr/internal/stats"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/adamkpickering/clsr/internal/views"
+	"github.com/gdamore/tcell/v2"
+	"github.com/spf13/cobra"
+)
+
+var statsFlags = struct {
+	DeckNames []string
+	TUI       bool
+}{}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringSliceVarP(&statsFlags.DeckNames, "decks", "d", []string{}, "only include cards from these decks")
+	statsCmd.Flags().BoolVar(&statsFlags.TUI, "tui", false, "show an interactive dashboard")
+	addSchedulerFlag(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics about reviews",
+	Long: fmt.Sprintf(`Show statistics computed from the review history of all cards,
+both overall and for each deck. True retention is the fraction of
+reviews of mature cards that were not failed; a card is mature if
+it had not been reviewed for at least %d days.`, stats.MatureInterval/(24*time.Hour)),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate deck source: %w", err)
+		}
+		clock, err := getClock()
+		if err != nil {
+			return err
+		}
+		allDecks, err := utils.GetDecks(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		scheduler, err := getScheduler(cfg, clock, allDecks)
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler: %w", err)
+		}
+		decks, err := utils.SelectDecks(allDecks, statsFlags.DeckNames...)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		if statsFlags.TUI {
+			return doStatsView(decks, scheduler, cfg, clock)
+		}
+
+		cards := []*models.Card{}
+		deckStats := make([]*stats.Stats, 0, len(decks))
+		for _, deck := range decks {
+			cards = append(cards, deck.Cards...)
+			thisDeckStats, err := stats.Compute(deck.Cards, scheduler, clock.Now(), cfg.DayStartHour)
+			if err != nil {
+				return fmt.Errorf("failed to compute stats for deck %q: %w", deck.Name, err)
+			}
+			deckStats = append(deckStats, thisDeckStats)
+		}
+		overallStats, err := stats.Compute(cards, scheduler, clock.Now(), cfg.DayStartHour)
+		if err != nil {
+			return fmt.Errorf("failed to compute overall stats: %w", err)
+		}
+
+		if err := printStats(overallStats); err != nil {
+			return err
+		}
+		fmt.Println()
+		return printDeckStatsTable(decks, deckStats)
+	},
+}
+
+func printStats(overallStats *stats.Stats) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	results := make([]string, 0, len(stats.Results))
+	for _, result := range stats.Results {
+		count := overallStats.ResultCounts[result]
+		results = append(results, fmt.Sprintf("%s %s (%d)", result, formatFraction(count, overallStats.ReviewCount), count))
+	}
+	retention := formatFraction(overallStats.MatureReviewPassed, overallStats.MatureReviewCount)
+	lines := []string{
+		fmt.Sprintf("Cards:\t%d", overallStats.CardCount),
+		fmt.Sprintf("Reviews:\t%d", overallStats.ReviewCount),
+		fmt.Sprintf("Reviews per day:\t%.1f", overallStats.ReviewsPerDay),
+		fmt.Sprintf("Results:\t%s", strings.Join(results, ", ")),
+		fmt.Sprintf("True retention:\t%s (of %d mature reviews)", retention, overallStats.MatureReviewCount),
+		fmt.Sprintf("Current streak:\t%d days", overallStats.CurrentStreak),
+		fmt.Sprintf("Longest streak:\t%d days", overallStats.LongestStreak),
+		fmt.Sprintf("Average interval:\t%s", formatInterval(overallStats.AverageInterval)),
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return fmt.Errorf("failed to write stats: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	return nil
+}
+
+func printDeckStatsTable(decks []*models.Deck, deckStats []*stats.Stats) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
+	_, err := fmt.Fprintln(writer, "Deck\tCards\tReviews\tReviews/Day\tFailed\tHard\tNormal\tEasy\tRetention\tStreak\tLongest Streak\tAverage Interval")
+	if err != nil {
+		return fmt.Errorf("failed to write header row: %w", err)
+	}
+	for i, deck := range decks {
+		s := deckStats[i]
+		_, err = fmt.Fprintf(writer, "%s\t%d\t%d\t%.1f\t%d\t%d\t%d\t%d\t%s\t%d\t%d\t%s\n",
+			deck.Name,
+			s.CardCount,
+			s.ReviewCount,
+			s.ReviewsPerDay,
+			s.ResultCounts[models.Failed],
+			s.ResultCounts[models.Hard],
+			s.ResultCounts[models.Normal],
+			s.ResultCounts[models.Easy],
+			formatFraction(s.MatureReviewPassed, s.MatureReviewCount),
+			s.CurrentStreak,
+			s.LongestStreak,
+			formatInterval(s.AverageInterval),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write row for deck %q: %w", deck.Name, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	return nil
+}
+
+// Returns numerator/denominator as a percentage, or "n/a" if
+// denominator is 0.
+func formatFraction(numerator, denominator int) string {
+	if denominator == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(numerator)/float64(denominator))
+}
+
+func formatInterval(interval time.Duration) string {
+	return fmt.Sprintf("%.1fd", interval.Hours()/24)
+}
+
+func doStatsView(decks []*models.Deck, scheduler scheduler.Scheduler, cfg *config.Config, clock clock.Clock) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return fmt.Errorf("failed to instantiate Screen: %w", err)
+	}
+	defer screen.Fini()
+	if err := screen.Init(); err != nil {
+		return fmt.Errorf("failed to initialize Screen: %w", err)
+	}
+	sv := &views.StatsView{
+		Screen:    screen,
+		Decks:     decks,
+		Scheduler: scheduler,
+		Config:    cfg,
+		Clock:     clock,
+	}
+	return sv.Run()
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 
+	"github.com/adamkpickering/clsr/internal/clock"
 	"github.com/adamkpickering/clsr/internal/config"
 	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
@@ -22,29 +24,48 @@ var studyFlags = struct {
 func init() {
 	rootCmd.AddCommand(studyCmd)
 	studyCmd.Flags().StringVarP(&studyFlags.DeckName, "deck", "d", "", "study a specific deck")
+	addSchedulerFlag(studyCmd)
+	addQueryFlag(studyCmd)
 }
 
 var studyCmd = &cobra.Command{
-	Use:   "study",
-	Short: "Study cards that are due",
-	Args:  cobra.NoArgs,
+	Use:         "study",
+	Short:       "Study cards that are due",
+	Annotations: writesDecks,
+	Args:        cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		deckName := studyFlags.DeckName
-		deckSource, err := deck_source.NewJSONFileDeckSource(deckDirectory)
+		if _, err := getQuery(cmd); err != nil {
+			return err
+		}
+		cfg, err := getConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		deckSource, err := getDeckSource(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate deck source: %w", err)
 		}
-		scheduler := scheduler.NewTwoReviewScheduler(config.DefaultConfig)
+		allDecks, err := utils.GetDecks(deckSource)
+		if err != nil {
+			return fmt.Errorf("failed to get decks: %w", err)
+		}
+		clock, err := getClock()
+		if err != nil {
+			return err
+		}
+		scheduler, err := getScheduler(cfg, clock, allDecks)
+		if err != nil {
+			return fmt.Errorf("failed to get scheduler: %w", err)
+		}
 
 		// get a list of decks
-		decks := []*models.Deck{}
+		decks := allDecks
 		if cmd.Flags().Changed("deck") {
-			decks, err = utils.GetDecks(deckSource, deckName)
-		} else {
-			decks, err = utils.GetDecks(deckSource)
-		}
-		if err != nil {
-			return fmt.Errorf("failed to get decks: %w", err)
+			decks, err = utils.SelectDecks(allDecks, deckName)
+			if err != nil {
+				return fmt.Errorf("failed to get decks: %w", err)
+			}
 		}
 
 		// get a list of cards with randomized order
@@ -52,38 +73,77 @@ var studyCmd = &cobra.Command{
 		for _, deck := range decks {
 			cards = append(cards, deck.Cards...)
 		}
+		cards, err = filterCardsByQuery(cmd, cards, scheduler, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to filter cards: %w", err)
+		}
 		rand.Shuffle(len(cards), func(i, j int) {
 			cards[i], cards[j] = cards[j], cards[i]
 		})
 
 		// study the cards
-		if err := doStudy(cards, scheduler); err != nil {
+		if err := doStudy(cards, scheduler, cfg, clock); err != nil {
 			return err
 		}
 
-		// write the changes to the decks
+		// Write the changes to the decks that were changed. A deck that
+		// cannot be written does not stop the other decks from being
+		// written.
+		savedDeckNames := []string{}
+		writeErrs := []error{}
 		for _, deck := range decks {
-			err = deckSource.WriteDeck(deck)
-			if err != nil {
-				return fmt.Errorf("failed to sync studied deck %q: %w", deck.Name, err)
+			if !deck.IsModified() {
+				continue
+			}
+			if err := writeStudiedDeck(deckSource, deck); err != nil {
+				writeErrs = append(writeErrs, fmt.Errorf("deck %q was not saved: %w", deck.Name, err))
+				continue
 			}
+			savedDeckNames = append(savedDeckNames, deck.Name)
+		}
+		if len(savedDeckNames) > 0 {
+			fmt.Printf("Saved changes to decks: %s\n", strings.Join(savedDeckNames, ", "))
+		}
+		if len(writeErrs) > 0 {
+			return fmt.Errorf("failed to save %d studied decks:\n%w", len(writeErrs), errors.Join(writeErrs...))
 		}
 
 		return nil
 	},
 }
 
+// Writes deck, which was studied. If the changes to deck conflict
+// with changes made to it by another process since it was read, only
+// the reviews from the study session are saved, since reviews can
+// always be merged; the other changes are discarded.
+func writeStudiedDeck(deckSource deck_source.DeckSource, deck *models.Deck) error {
+	err := deckSource.WriteDeck(deck)
+	if !errors.Is(err, deck_source.ErrConflict) {
+		return err
+	}
+	currentDeck, readErr := deckSource.ReadDeck(deck.Name)
+	if readErr != nil {
+		return fmt.Errorf("failed to read deck after conflict: %w", readErr)
+	}
+	deck_source.MergeReviews(currentDeck, deck)
+	if err := deckSource.WriteDeck(currentDeck); err != nil {
+		return fmt.Errorf("failed to save reviews after conflict: %w", err)
+	}
+	fmt.Printf("Deck %q was changed elsewhere during the session (%s); only its reviews were saved\n", deck.Name, err)
+	return nil
+}
+
 // Runs the study TUI until there is an error, we run out of cards
 // to review, the user quits, or the user edits a card. If the user
 // chooses to edit a card, it allows them to do so, and then resumes
 // studying by calling itself. Uses recursion because it is the
 // cleanest solution for re-evaluating the set of cards that need to
 // be studied each time the user edits a card.
-func doStudy(cards []*models.Card, scheduler scheduler.Scheduler) error {
-	// get only cards that are due to be studied
+func doStudy(cards []*models.Card, scheduler scheduler.Scheduler, cfg *config.Config, clock clock.Clock) error {
+	// get only cards that are due to be studied today
 	cardsToStudy := make([]*models.Card, 0, len(cards))
 	for _, card := range cards {
-		isDue, err := scheduler.IsDue(card)
+		isDue, err := views.IsDueToday(card, scheduler, cfg, clock)
 		if err != nil {
 			return fmt.Errorf("failed to determine whether card %q is due: %w", card.ID, err)
 		}
@@ -92,7 +152,7 @@ func doStudy(cards []*models.Card, scheduler scheduler.Scheduler) error {
 		}
 	}
 
-	if cardID, err := doStudyFragment(cardsToStudy, scheduler); errors.Is(err, views.ErrExit) {
+	if cardID, err := doStudyFragment(cardsToStudy, scheduler, cfg, clock); errors.Is(err, views.ErrExit) {
 		return nil
 	} else if errors.Is(err, views.ErrEdit) {
 		card, err := getCardByID(cardID, cardsToStudy)
@@ -102,7 +162,7 @@ func doStudy(cards []*models.Card, scheduler scheduler.Scheduler) error {
 		if err := models.EditCardViaEditor(card); err != nil && !errors.Is(err, models.ErrNotModified) {
 			return fmt.Errorf("failed to edit card %q: %w", cardID, err)
 		}
-		if err := doStudy(cardsToStudy, scheduler); err != nil {
+		if err := doStudy(cardsToStudy, scheduler, cfg, clock); err != nil {
 			return err
 		}
 	} else if err != nil {
@@ -113,7 +173,7 @@ func doStudy(cards []*models.Card, scheduler scheduler.Scheduler) error {
 
 // This is a separate function because it allows screen.Fini() to be
 // called as a deferred function.
-func doStudyFragment(cardsToStudy []*models.Card, scheduler scheduler.Scheduler) (string, error) {
+func doStudyFragment(cardsToStudy []*models.Card, scheduler scheduler.Scheduler, cfg *config.Config, clock clock.Clock) (string, error) {
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		return "", fmt.Errorf("failed to instantiate Screen: %w", err)
@@ -126,6 +186,8 @@ func doStudyFragment(cardsToStudy []*models.Card, scheduler scheduler.Scheduler)
 		Screen:    screen,
 		Cards:     cardsToStudy,
 		Scheduler: scheduler,
+		Config:    cfg,
+		Clock:     clock,
 	}
 	return ss.Run()
 }
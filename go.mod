@@ -5,6 +5,8 @@ go 1.21
 require (
 	github.com/gdamore/tcell/v2 v2.7.4
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/sys v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -14,7 +16,6 @@ require (
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/rivo/uniseg v0.4.4 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/sys v0.17.0 // indirect
 	golang.org/x/term v0.17.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
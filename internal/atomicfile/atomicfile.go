@@ -0,0 +1,86 @@
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// The suffix that is added to the path of a file to get the path of
+// its backup.
+const BackupSuffix = ".bak"
+
+// Writes contents to the file at path, so that the file is never left
+// partly written: contents are written to a temporary file in the same
+// directory, synced to disk, and then renamed over the file at path.
+// If the file at path already exists, its permissions are kept;
+// otherwise it is created with perm. If backup is true and the file
+// at path already exists, its previous contents are kept in a file
+// with the same path plus BackupSuffix.
+func WriteFile(path string, contents []byte, perm fs.FileMode, backup bool) error {
+	directory := filepath.Dir(path)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to stat %q: %w", path, err)
+	}
+
+	tempFile, err := os.CreateTemp(directory, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tempPath)
+		}
+	}()
+
+	if _, err := tempFile.Write(contents); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tempFile.Chmod(perm); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to set permissions of temporary file: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if backup {
+		previousContents, err := os.ReadFile(path)
+		if err == nil {
+			if err := WriteFile(path+BackupSuffix, previousContents, perm, false); err != nil {
+				return fmt.Errorf("failed to write backup: %w", err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read previous contents for backup: %w", err)
+		}
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+	renamed = true
+	syncDirectory(directory)
+	return nil
+}
+
+// Syncs directory so that a rename within it is on disk. This is
+// best effort, since directories cannot be synced on some platforms.
+func syncDirectory(directory string) {
+	dir, err := os.Open(directory)
+	if err != nil {
+		return
+	}
+	defer dir.Close()
+	dir.Sync()
+}
@@ -0,0 +1,82 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Run("NewFile", func(t *testing.T) {
+		directory := t.TempDir()
+		path := filepath.Join(directory, "deck.json")
+		if err := WriteFile(path, []byte("new"), 0640, true); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat file: %s", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0640 {
+			t.Errorf("got permissions %o but expected 640", perm)
+		}
+		if _, err := os.Stat(path + BackupSuffix); err == nil {
+			t.Errorf("expected no backup of a file that did not exist")
+		}
+	})
+
+	t.Run("ReplacesFile", func(t *testing.T) {
+		directory := t.TempDir()
+		path := filepath.Join(directory, "deck.json")
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatalf("failed to write initial file: %s", err)
+		}
+		if err := WriteFile(path, []byte("new"), 0644, true); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+		checkContents(t, path, "new")
+		checkContents(t, path+BackupSuffix, "old")
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat file: %s", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("got permissions %o but expected the original 600", perm)
+		}
+
+		// check that no temporary files are left behind
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			t.Fatalf("failed to read directory: %s", err)
+		}
+		if len(entries) != 2 {
+			t.Errorf("got %d files in directory but expected 2", len(entries))
+		}
+	})
+
+	t.Run("NoBackup", func(t *testing.T) {
+		directory := t.TempDir()
+		path := filepath.Join(directory, "deck.json")
+		if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+			t.Fatalf("failed to write initial file: %s", err)
+		}
+		if err := WriteFile(path, []byte("new"), 0644, false); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+		checkContents(t, path, "new")
+		if _, err := os.Stat(path + BackupSuffix); err == nil {
+			t.Errorf("expected no backup")
+		}
+	})
+}
+
+func checkContents(t *testing.T, path, expected string) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %q: %s", path, err)
+	}
+	if string(contents) != expected {
+		t.Errorf("got contents %q for %q but expected %q", contents, path, expected)
+	}
+}
@@ -0,0 +1,37 @@
+package clock
+
+import "time"
+
+// A Clock tells the current time. Code that needs the current time
+// should get it from a Clock rather than from time.Now, so that it can
+// be run as if it were a different time.
+type Clock interface {
+	Now() time.Time
+}
+
+// A Clock that returns the actual current time.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// A Clock that always returns the time it is set to.
+type FixedClock struct {
+	time time.Time
+}
+
+func NewFixedClock(t time.Time) *FixedClock {
+	return &FixedClock{
+		time: t,
+	}
+}
+
+func (clock *FixedClock) Now() time.Time {
+	return clock.time
+}
+
+// Changes the time that the clock returns.
+func (clock *FixedClock) Set(t time.Time) {
+	clock.time = t
+}
@@ -1,10 +1,55 @@
 package config
 
+import (
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
 type Config struct {
+	// The name of the scheduler to use. See scheduler.SchedulerNames
+	// for the possible values.
+	Scheduler string `yaml:"scheduler"`
+	// The hour (0-23) at which a new day starts. For example, if this
+	// is 4, a review at 00:30 counts as a review on the previous day,
+	// and cards due on a day become due at 04:00 on that day.
+	DayStartHour uint `yaml:"day_start_hour"`
+	// The delays between reviews of a card that has never been reviewed
+	// successfully, for example [1m, 10m]. Each successful review moves
+	// the card on to the next step, and a card that completes every step
+	// graduates to being scheduled by the scheduler. Failing a review
+	// moves the card back to the first step. Empty means that new cards
+	// are scheduled by the scheduler from their first review.
+	LearningSteps []time.Duration `yaml:"learning_steps"`
+	// The same as LearningSteps, but for cards whose review was failed
+	// after they graduated. Empty means that failed reviews are
+	// scheduled by the scheduler.
+	RelearningSteps []time.Duration `yaml:"relearning_steps"`
+	// Once every due card in a study session has been studied, cards
+	// that will be due within this duration are studied early rather
+	// than ending the session.
+	LearnAheadLimit time.Duration `yaml:"learn_ahead_limit"`
 	// The time in hours until a card is due after a review has been failed.
-	FailedReviewInterval  uint
-	SecondReviewIntervals SecondReviewIntervals
-	IntervalMultipliers   IntervalMultipliers
+	FailedReviewInterval  uint                  `yaml:"failed_review_interval"`
+	SecondReviewIntervals SecondReviewIntervals `yaml:"second_review_intervals"`
+	IntervalMultipliers   IntervalMultipliers   `yaml:"interval_multipliers"`
+	// The maximum fraction by which intervals of a day or more are
+	// randomly lengthened or shortened, so that cards that were reviewed
+	// together do not stay together. For example, 0.05 means up to 5%
+	// shorter or longer. 0 disables fuzzing.
+	FuzzFactor  float64           `yaml:"fuzz_factor"`
+	LoadBalance LoadBalanceConfig `yaml:"load_balance"`
+	Leech       LeechConfig       `yaml:"leech"`
+	// If true, the previous version of each deck file is kept with
+	// ".bak" added to its name whenever the deck is written.
+	BackupDecks bool `yaml:"backup_decks"`
+	// The format that decks are written in: "json" or "yaml". Decks in
+	// either format are read, so that a data directory can be moved
+	// from one format to the other one deck at a time.
+	DeckFormat string        `yaml:"deck_format"`
+	SM2        SM2Config     `yaml:"sm2"`
+	FSRS       FSRSConfig    `yaml:"fsrs"`
+	Leitner    LeitnerConfig `yaml:"leitner"`
 }
 
 // This applies when the card has been reviewed exactly once,
@@ -12,9 +57,9 @@ type Config struct {
 // The time until the next review is the value below that corresponds
 // to the Result of the one review. Values are in hours.
 type SecondReviewIntervals struct {
-	Hard   uint
-	Normal uint
-	Easy   uint
+	Hard   uint `yaml:"hard"`
+	Normal uint `yaml:"normal"`
+	Easy   uint `yaml:"easy"`
 }
 
 // This applies when a card has been reviewed 2 or more times.
@@ -23,12 +68,111 @@ type SecondReviewIntervals struct {
 // and multiplying that difference by the below multiplier that
 // corresponds to the Result on the last review.
 type IntervalMultipliers struct {
-	Hard   float64
-	Normal float64
-	Easy   float64
+	Hard   float64 `yaml:"hard"`
+	Normal float64 `yaml:"normal"`
+	Easy   float64 `yaml:"easy"`
 }
 
+// Configures load balancing, which moves the next review of each card
+// to the day with the fewest other reviews due within a window around
+// the day it would otherwise be due.
+type LoadBalanceConfig struct {
+	// The size of the window on each side of the day a card is due, as
+	// a fraction of the card's interval. 0 disables load balancing.
+	WindowFactor float64 `yaml:"window_factor"`
+	// The maximum size of the window on each side of the day a card
+	// is due, in days.
+	MaximumWindow uint `yaml:"maximum_window"`
+}
+
+// The possible values of LeechConfig.Action.
+const (
+	LeechActionTag     = "tag"
+	LeechActionSuspend = "suspend"
+	LeechActionWarn    = "warn"
+)
+
+var LeechActions = []string{LeechActionTag, LeechActionSuspend, LeechActionWarn}
+
+// The possible values of Config.DeckFormat.
+const (
+	DeckFormatJSON = "json"
+	DeckFormatYAML = "yaml"
+)
+
+var DeckFormats = []string{DeckFormatJSON, DeckFormatYAML}
+
+// Configures the handling of leeches: cards whose reviews are failed
+// so often that they are probably worth rewriting.
+type LeechConfig struct {
+	// The number of failed reviews at which a card becomes a leech.
+	// 0 disables leech detection.
+	Threshold uint `yaml:"threshold"`
+	// What happens when a review makes a card a leech. "tag" adds the
+	// leech tag to the card, "suspend" also makes the card inactive,
+	// and "warn" only shows a warning.
+	Action string `yaml:"action"`
+}
+
+// Configures the SM-2 scheduler. Lapses (failed reviews) use
+// FailedReviewInterval from the enclosing Config.
+type SM2Config struct {
+	// The ease factor that a card starts out with.
+	InitialEaseFactor float64 `yaml:"initial_ease_factor"`
+	// The ease factor of a card never drops below this value.
+	MinimumEaseFactor float64 `yaml:"minimum_ease_factor"`
+	// The interval in hours after the first successful review
+	// in a row.
+	FirstInterval uint `yaml:"first_interval"`
+	// The interval in hours after the second successful review
+	// in a row. Intervals after this are calculated by multiplying
+	// the previous interval by the ease factor.
+	SecondInterval uint `yaml:"second_interval"`
+}
+
+// Configures the FSRS scheduler. Lapses (failed reviews) use
+// FailedReviewInterval from the enclosing Config.
+type FSRSConfig struct {
+	// The 17 model weights of FSRS v4.5.
+	Weights []float64 `yaml:"weights"`
+	// The probability of recalling a card that the scheduler aims
+	// for at the time of its next review. Between 0 and 1.
+	RequestRetention float64 `yaml:"request_retention"`
+	// The maximum interval between reviews in days.
+	MaximumInterval uint `yaml:"maximum_interval"`
+}
+
+// Configures the Leitner scheduler.
+type LeitnerConfig struct {
+	// The interval in hours until a card in each box is due. The
+	// number of boxes is the length of this slice. Cards start out
+	// in the first box.
+	BoxIntervals []uint `yaml:"box_intervals"`
+}
+
+// Converts durations to a slice of time.Duration.
+func toDurations(durations []models.Duration) []time.Duration {
+	newDurations := make([]time.Duration, 0, len(durations))
+	for _, duration := range durations {
+		newDurations = append(newDurations, time.Duration(duration))
+	}
+	return newDurations
+}
+
+// The default FSRS v4.5 weights, as published by the FSRS project.
+var DefaultFSRSWeights = []float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// The values of every config option that is not set by a config file.
+// This should not be modified; use Copy to get a Config that may be.
 var DefaultConfig = &Config{
+	Scheduler:            "two-review",
+	DayStartHour:         0,
+	LearningSteps:        []time.Duration{},
+	RelearningSteps:      []time.Duration{},
+	LearnAheadLimit:      20 * time.Minute,
 	FailedReviewInterval: 4,
 	SecondReviewIntervals: SecondReviewIntervals{
 		Hard:   4,
@@ -40,4 +184,101 @@ var DefaultConfig = &Config{
 		Normal: 1.5,
 		Easy:   2.0,
 	},
+	LoadBalance: LoadBalanceConfig{
+		WindowFactor:  0,
+		MaximumWindow: 7,
+	},
+	Leech: LeechConfig{
+		Threshold: 8,
+		Action:    LeechActionTag,
+	},
+	DeckFormat: DeckFormatJSON,
+	SM2: SM2Config{
+		InitialEaseFactor: 2.5,
+		MinimumEaseFactor: 1.3,
+		FirstInterval:     24,
+		SecondInterval:    144,
+	},
+	FSRS: FSRSConfig{
+		Weights:          DefaultFSRSWeights,
+		RequestRetention: 0.9,
+		MaximumInterval:  36500,
+	},
+	Leitner: LeitnerConfig{
+		BoxIntervals: []uint{24, 72, 168, 336, 720},
+	},
+}
+
+// Returns a deep copy of config.
+func (config *Config) Copy() *Config {
+	newConfig := *config
+	newConfig.LearningSteps = make([]time.Duration, len(config.LearningSteps))
+	copy(newConfig.LearningSteps, config.LearningSteps)
+	newConfig.RelearningSteps = make([]time.Duration, len(config.RelearningSteps))
+	copy(newConfig.RelearningSteps, config.RelearningSteps)
+	newConfig.FSRS.Weights = make([]float64, len(config.FSRS.Weights))
+	copy(newConfig.FSRS.Weights, config.FSRS.Weights)
+	newConfig.Leitner.BoxIntervals = make([]uint, len(config.Leitner.BoxIntervals))
+	copy(newConfig.Leitner.BoxIntervals, config.Leitner.BoxIntervals)
+	return &newConfig
+}
+
+// Returns a copy of config with the set values of overrides, which
+// are the config values of a deck, applied to it. If overrides is nil,
+// the copy is returned unchanged.
+func (config *Config) WithOverrides(overrides *models.DeckConfig) *Config {
+	newConfig := config.Copy()
+	if overrides == nil {
+		return newConfig
+	}
+
+	if overrides.Scheduler != "" {
+		newConfig.Scheduler = overrides.Scheduler
+	}
+	if len(overrides.LearningSteps) > 0 {
+		newConfig.LearningSteps = toDurations(overrides.LearningSteps)
+	}
+	if len(overrides.RelearningSteps) > 0 {
+		newConfig.RelearningSteps = toDurations(overrides.RelearningSteps)
+	}
+	if overrides.FailedReviewInterval != 0 {
+		newConfig.FailedReviewInterval = overrides.FailedReviewInterval
+	}
+	if intervals := overrides.SecondReviewIntervals; intervals != nil {
+		setIfNonZero(&newConfig.SecondReviewIntervals.Hard, intervals.Hard)
+		setIfNonZero(&newConfig.SecondReviewIntervals.Normal, intervals.Normal)
+		setIfNonZero(&newConfig.SecondReviewIntervals.Easy, intervals.Easy)
+	}
+	if multipliers := overrides.IntervalMultipliers; multipliers != nil {
+		setIfNonZero(&newConfig.IntervalMultipliers.Hard, multipliers.Hard)
+		setIfNonZero(&newConfig.IntervalMultipliers.Normal, multipliers.Normal)
+		setIfNonZero(&newConfig.IntervalMultipliers.Easy, multipliers.Easy)
+	}
+	if sm2 := overrides.SM2; sm2 != nil {
+		setIfNonZero(&newConfig.SM2.InitialEaseFactor, sm2.InitialEaseFactor)
+		setIfNonZero(&newConfig.SM2.MinimumEaseFactor, sm2.MinimumEaseFactor)
+		setIfNonZero(&newConfig.SM2.FirstInterval, sm2.FirstInterval)
+		setIfNonZero(&newConfig.SM2.SecondInterval, sm2.SecondInterval)
+	}
+	if fsrs := overrides.FSRS; fsrs != nil {
+		if len(fsrs.Weights) > 0 {
+			newConfig.FSRS.Weights = make([]float64, len(fsrs.Weights))
+			copy(newConfig.FSRS.Weights, fsrs.Weights)
+		}
+		setIfNonZero(&newConfig.FSRS.RequestRetention, fsrs.RequestRetention)
+		setIfNonZero(&newConfig.FSRS.MaximumInterval, fsrs.MaximumInterval)
+	}
+	setIfNonZero(&newConfig.FuzzFactor, overrides.FuzzFactor)
+	if leitner := overrides.Leitner; leitner != nil && len(leitner.BoxIntervals) > 0 {
+		newConfig.Leitner.BoxIntervals = make([]uint, len(leitner.BoxIntervals))
+		copy(newConfig.Leitner.BoxIntervals, leitner.BoxIntervals)
+	}
+
+	return newConfig
+}
+
+func setIfNonZero[T uint | float64](destination *T, value T) {
+	if value != 0 {
+		*destination = value
+	}
 }
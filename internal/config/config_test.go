@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestWithOverrides(t *testing.T) {
+	t.Run("Nil", func(t *testing.T) {
+		config := DefaultConfig.WithOverrides(nil)
+		if config == DefaultConfig {
+			t.Errorf("got DefaultConfig instead of a copy of it")
+		}
+		if config.Scheduler != DefaultConfig.Scheduler {
+			t.Errorf("got scheduler %q but expected %q", config.Scheduler, DefaultConfig.Scheduler)
+		}
+	})
+
+	t.Run("SetValues", func(t *testing.T) {
+		overrides := &models.DeckConfig{
+			Scheduler:           "sm2",
+			IntervalMultipliers: &models.IntervalMultipliers{Easy: 4.0},
+			Leitner:             &models.LeitnerConfig{BoxIntervals: []uint{1, 2}},
+		}
+		config := DefaultConfig.WithOverrides(overrides)
+		if config.Scheduler != "sm2" {
+			t.Errorf("got scheduler %q but expected sm2", config.Scheduler)
+		}
+		if config.IntervalMultipliers.Easy != 4.0 {
+			t.Errorf("got easy multiplier %g but expected 4.0", config.IntervalMultipliers.Easy)
+		}
+		if config.IntervalMultipliers.Normal != DefaultConfig.IntervalMultipliers.Normal {
+			t.Errorf("got normal multiplier %g but expected default of %g", config.IntervalMultipliers.Normal, DefaultConfig.IntervalMultipliers.Normal)
+		}
+		if config.FailedReviewInterval != DefaultConfig.FailedReviewInterval {
+			t.Errorf("got failed review interval %d but expected default of %d", config.FailedReviewInterval, DefaultConfig.FailedReviewInterval)
+		}
+		if len(config.Leitner.BoxIntervals) != 2 {
+			t.Errorf("got %d Leitner boxes but expected 2", len(config.Leitner.BoxIntervals))
+		}
+		overrides.Leitner.BoxIntervals[0] = 100
+		if config.Leitner.BoxIntervals[0] != 1 {
+			t.Errorf("changing overrides changed the config they were applied to")
+		}
+	})
+	t.Run("SchedulingValues", func(t *testing.T) {
+		overrides := &models.DeckConfig{
+			LearningSteps: []models.Duration{models.Duration(time.Minute), models.Duration(10 * time.Minute)},
+			FuzzFactor:    0.1,
+		}
+		config := DefaultConfig.WithOverrides(overrides)
+		if len(config.LearningSteps) != 2 || config.LearningSteps[1] != 10*time.Minute {
+			t.Errorf("got learning steps %v but expected [1m0s 10m0s]", config.LearningSteps)
+		}
+		if config.FuzzFactor != 0.1 {
+			t.Errorf("got fuzz factor %g but expected 0.1", config.FuzzFactor)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// The name of the config file that is read from the data directory.
+const FileName = ".clsr.yaml"
+
+// Returns the path of the config file in dataDirectory.
+// The file may not exist.
+func GetDataDirectoryFilePath(dataDirectory string) string {
+	return filepath.Join(dataDirectory, FileName)
+}
+
+// Returns the path of the config file that applies to every data
+// directory of the current user. This is $XDG_CONFIG_HOME/clsr/config.yaml
+// on Linux. The file may not exist.
+func GetUserFilePath() (string, error) {
+	userConfigDirectory, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(userConfigDirectory, "clsr", "config.yaml"), nil
+}
+
+// Returns the paths of the config files that exist for dataDirectory,
+// ordered from lowest to highest precedence.
+func GetFilePaths(dataDirectory string) ([]string, error) {
+	candidatePaths := []string{GetDataDirectoryFilePath(dataDirectory)}
+	if userFilePath, err := GetUserFilePath(); err == nil {
+		candidatePaths = append([]string{userFilePath}, candidatePaths...)
+	}
+
+	filePaths := []string{}
+	for _, candidatePath := range candidatePaths {
+		_, err := os.Stat(candidatePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return []string{}, fmt.Errorf("failed to stat %q: %w", candidatePath, err)
+		}
+		filePaths = append(filePaths, candidatePath)
+	}
+	return filePaths, nil
+}
+
+// Returns a copy of DefaultConfig with the contents of the user config
+// file and then the config file in dataDirectory merged over it. Values
+// that are not set in either file keep their default values. Returns
+// an error if either file cannot be parsed, or if the resulting Config
+// is not valid.
+func Load(dataDirectory string) (*Config, error) {
+	config, _, err := LoadWithSources(dataDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return config, nil
+}
+
+// Does the same thing as Load, except that the resulting Config is
+// not validated. Also returns the Sources of the values in the Config.
+func LoadWithSources(dataDirectory string) (*Config, Sources, error) {
+	return loadWithSources(dataDirectory, nil)
+}
+
+// Does the same thing as LoadWithSources, except that if
+// dataDirectoryContents is not nil, it is used as the contents of
+// the config file in dataDirectory instead of what is on disk.
+func loadWithSources(dataDirectory string, dataDirectoryContents []byte) (*Config, Sources, error) {
+	filePaths, err := GetFilePaths(dataDirectory)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find config files: %w", err)
+	}
+	dataDirectoryFilePath := GetDataDirectoryFilePath(dataDirectory)
+	if dataDirectoryContents != nil && !slices.Contains(filePaths, dataDirectoryFilePath) {
+		filePaths = append(filePaths, dataDirectoryFilePath)
+	}
+
+	config := DefaultConfig.Copy()
+	sources := Sources{}
+	for _, filePath := range filePaths {
+		contents := dataDirectoryContents
+		if contents == nil || filePath != dataDirectoryFilePath {
+			contents, err = os.ReadFile(filePath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+		}
+		if err := mergeYAML(config, contents); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file %q: %w", filePath, err)
+		}
+		keys, err := getYAMLKeys(contents)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file %q: %w", filePath, err)
+		}
+		for _, key := range keys {
+			sources[key] = filePath
+		}
+	}
+
+	return config, sources, nil
+}
+
+// Decodes contents into config. Values that are not present in
+// contents are left as they are. Unknown keys are an error.
+func mergeYAML(config *Config, contents []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Sets key to value in the config file in dataDirectory, creating the
+// file if it does not exist. key is a dot-separated path such as
+// "fsrs.weights". Other contents of the file, including comments, are
+// preserved. Returns an error without changing the file if key is not
+// a known config key, or if the new value would make the config that
+// is loaded from both config files invalid.
+func SetFileValue(dataDirectory string, key string, value any) error {
+	filePath := GetDataDirectoryFilePath(dataDirectory)
+	document := &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+	}
+	contents, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if len(strings.TrimSpace(string(contents))) > 0 {
+		if err := yaml.Unmarshal(contents, document); err != nil {
+			return fmt.Errorf("failed to parse config file %q: %w", filePath, err)
+		}
+	}
+
+	if err := setYAMLValue(document, key, value); err != nil {
+		return err
+	}
+
+	// check that the new contents are valid before writing them
+	newContents, err := marshalYAML(document)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config file: %w", err)
+	}
+	config, _, err := loadWithSources(dataDirectory, newContents)
+	if err != nil {
+		return fmt.Errorf("failed to set %q: %w", key, err)
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(filePath, newContents, 0644, false); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+func marshalYAML(value any) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("NoFiles", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		config, err := Load(t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to load config: %s", err)
+		}
+		if config.FailedReviewInterval != DefaultConfig.FailedReviewInterval {
+			t.Errorf("got failed review interval %d but expected default of %d", config.FailedReviewInterval, DefaultConfig.FailedReviewInterval)
+		}
+	})
+
+	t.Run("MergesFiles", func(t *testing.T) {
+		userConfigDirectory := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", userConfigDirectory)
+		userFileContents := "failed_review_interval: 2\ninterval_multipliers:\n  easy: 3.0\n"
+		writeTestFile(t, filepath.Join(userConfigDirectory, "clsr", "config.yaml"), userFileContents)
+		dataDirectory := t.TempDir()
+		writeTestFile(t, filepath.Join(dataDirectory, FileName), "interval_multipliers:\n  normal: 2.5\n")
+
+		config, err := Load(dataDirectory)
+		if err != nil {
+			t.Fatalf("failed to load config: %s", err)
+		}
+		if config.FailedReviewInterval != 2 {
+			t.Errorf("got failed review interval %d but expected 2", config.FailedReviewInterval)
+		}
+		if config.IntervalMultipliers.Easy != 3.0 {
+			t.Errorf("got easy multiplier %g but expected 3.0", config.IntervalMultipliers.Easy)
+		}
+		if config.IntervalMultipliers.Normal != 2.5 {
+			t.Errorf("got normal multiplier %g but expected 2.5", config.IntervalMultipliers.Normal)
+		}
+		if config.IntervalMultipliers.Hard != DefaultConfig.IntervalMultipliers.Hard {
+			t.Errorf("got hard multiplier %g but expected default of %g", config.IntervalMultipliers.Hard, DefaultConfig.IntervalMultipliers.Hard)
+		}
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		dataDirectory := t.TempDir()
+		writeTestFile(t, filepath.Join(dataDirectory, FileName), "failed_review_intervals: 2\n")
+		if _, err := Load(dataDirectory); err == nil {
+			t.Errorf("expected error for unknown key")
+		}
+	})
+
+	t.Run("InvalidValues", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		dataDirectory := t.TempDir()
+		writeTestFile(t, filepath.Join(dataDirectory, FileName), "failed_review_interval: 0\ninterval_multipliers:\n  hard: 0.5\nleech:\n  action: delete\n")
+		_, err := Load(dataDirectory)
+		if err == nil {
+			t.Fatalf("expected error for invalid values")
+		}
+		for _, key := range []string{"failed_review_interval", "interval_multipliers.hard", "leech.action"} {
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("error %q does not mention %s", err, key)
+			}
+		}
+	})
+}
+
+func TestSetFileValue(t *testing.T) {
+	t.Run("PreservesContents", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		dataDirectory := t.TempDir()
+		filePath := filepath.Join(dataDirectory, FileName)
+		writeTestFile(t, filePath, "# my comment\nfailed_review_interval: 2\n")
+		if err := SetFileValue(dataDirectory, "fsrs.request_retention", 0.85); err != nil {
+			t.Fatalf("failed to set value: %s", err)
+		}
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("failed to read config file: %s", err)
+		}
+		for _, expected := range []string{"# my comment", "failed_review_interval: 2", "request_retention: 0.85"} {
+			if !strings.Contains(string(contents), expected) {
+				t.Errorf("config file does not contain %q:\n%s", expected, contents)
+			}
+		}
+	})
+
+	t.Run("RejectsInvalid", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		dataDirectory := t.TempDir()
+		filePath := filepath.Join(dataDirectory, FileName)
+		if err := SetFileValue(dataDirectory, "not_a_key", 1); err == nil {
+			t.Errorf("expected error for unknown key")
+		}
+		if err := SetFileValue(dataDirectory, "fsrs.request_retention", 1.5); err == nil {
+			t.Errorf("expected error for invalid value")
+		}
+		if _, err := os.Stat(filePath); err == nil {
+			t.Errorf("config file was written despite errors")
+		}
+	})
+
+	t.Run("ValidatesMergedConfig", func(t *testing.T) {
+		// valid with the defaults, but not with the user config file
+		userConfigDirectory := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", userConfigDirectory)
+		writeTestFile(t, filepath.Join(userConfigDirectory, "clsr", "config.yaml"), "sm2:\n  minimum_ease_factor: 2.0\n")
+		dataDirectory := t.TempDir()
+		if err := SetFileValue(dataDirectory, "sm2.initial_ease_factor", 1.5); err == nil {
+			t.Errorf("expected error for value that is invalid with the user config file")
+		}
+		if _, err := os.Stat(filepath.Join(dataDirectory, FileName)); err == nil {
+			t.Errorf("config file was written despite errors")
+		}
+	})
+}
+
+func writeTestFile(t *testing.T, filePath, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("failed to create directory: %s", err)
+	}
+	if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write %q: %s", filePath, err)
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// The source of config values that are not set anywhere else.
+const DefaultSource = "default"
+
+// Maps the dot-separated keys of config values, such as
+// "interval_multipliers.easy", to where those values were set.
+// Keys that are not present have the value DefaultSource.
+type Sources map[string]string
+
+// Returns the source of the value with the given key.
+func (sources Sources) Get(key string) string {
+	if source, ok := sources[key]; ok {
+		return source
+	}
+	return DefaultSource
+}
+
+// A single config value.
+type Entry struct {
+	// The dot-separated key of the value, such as "fsrs.weights".
+	Key string
+	// The value, formatted as it would be in a config file.
+	Value string
+}
+
+// Returns every value in config, in the order that they are defined.
+func (config *Config) Entries() ([]Entry, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(config); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	entries := []Entry{}
+	if err := flattenYAML(node, "", &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Returns the keys of the values that are set in overrides, which are
+// the config values of a deck.
+func OverrideKeys(overrides *models.DeckConfig) ([]string, error) {
+	if overrides == nil {
+		return []string{}, nil
+	}
+	// JSON is used because every field of DeckConfig is omitted from
+	// JSON when it is unset. Since JSON is valid YAML, it can then be
+	// parsed as YAML.
+	contents, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal overrides: %w", err)
+	}
+	return getYAMLKeys(contents)
+}
+
+// Returns a copy of overrides, which are the config values of a deck,
+// with key set to value. key is a dot-separated path such as
+// "interval_multipliers.easy".
+func SetOverride(overrides *models.DeckConfig, key string, value any) (*models.DeckConfig, error) {
+	contents := []byte("{}")
+	if overrides != nil {
+		var err error
+		contents, err = json.Marshal(overrides)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal overrides: %w", err)
+		}
+	}
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, document); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides: %w", err)
+	}
+	if err := setYAMLValue(document, key, value); err != nil {
+		return nil, err
+	}
+	newContents, err := marshalYAML(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal overrides: %w", err)
+	}
+	newOverrides := &models.DeckConfig{}
+	decoder := yaml.NewDecoder(bytes.NewReader(newContents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(newOverrides); err != nil {
+		return nil, fmt.Errorf("failed to set %q: %w", key, err)
+	}
+	return newOverrides, nil
+}
+
+// Parses a value passed on the command line into a value that can be
+// passed to SetFileValue or SetOverride. The value is parsed as YAML,
+// so that for example "1.5" becomes a number and "[1, 2]" becomes a list.
+func ParseValue(rawValue string) (any, error) {
+	var value any
+	if err := yaml.Unmarshal([]byte(rawValue), &value); err != nil {
+		return nil, fmt.Errorf("failed to parse value %q: %w", rawValue, err)
+	}
+	return value, nil
+}
+
+// Returns the dot-separated keys of every value in a YAML document.
+func getYAMLKeys(contents []byte) ([]string, error) {
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, document); err != nil {
+		return nil, err
+	}
+	entries := []Entry{}
+	if err := flattenYAML(document, "", &entries); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+// Appends an Entry to entries for every value in node. Mappings are
+// descended into; anything else is treated as a single value.
+func flattenYAML(node *yaml.Node, prefix string, entries *[]Entry) error {
+	switch node.Kind {
+	case 0:
+		return nil
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := flattenYAML(child, prefix, entries); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flattenYAML(node.Content[i+1], key, entries); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		valueNode := *node
+		if valueNode.Kind == yaml.SequenceNode {
+			valueNode.Style = yaml.FlowStyle
+		}
+		value, err := yaml.Marshal(&valueNode)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value of %q: %w", prefix, err)
+		}
+		*entries = append(*entries, Entry{Key: prefix, Value: strings.TrimSpace(string(value))})
+		return nil
+	}
+}
+
+// Sets key, a dot-separated path, to value in the passed YAML document.
+// Mappings that do not exist along the path are created.
+func setYAMLValue(document *yaml.Node, key string, value any) error {
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 {
+		return fmt.Errorf("cannot set %q: not a YAML document", key)
+	}
+
+	// find or create the node for the key
+	node := document.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set %q: parent is not a mapping", key)
+		}
+		var childNode *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				childNode = node.Content[i+1]
+				break
+			}
+		}
+		if childNode == nil {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}
+			childNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, keyNode, childNode)
+		}
+		node = childNode
+	}
+
+	// set the value
+	valueNode := &yaml.Node{}
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+	if valueNode.Kind == yaml.SequenceNode {
+		valueNode.Style = yaml.FlowStyle
+	}
+	valueNode.HeadComment = node.HeadComment
+	valueNode.LineComment = node.LineComment
+	*node = *valueNode
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestOverrideKeys(t *testing.T) {
+	t.Run("SetOverride", func(t *testing.T) {
+		overrides, err := SetOverride(nil, "interval_multipliers.easy", 3.0)
+		if err != nil {
+			t.Fatalf("failed to set value: %s", err)
+		}
+		overrides, err = SetOverride(overrides, "scheduler", "fsrs")
+		if err != nil {
+			t.Fatalf("failed to set value: %s", err)
+		}
+		if overrides.IntervalMultipliers == nil || overrides.IntervalMultipliers.Easy != 3.0 {
+			t.Errorf("interval_multipliers.easy was not set to 3.0")
+		}
+		if overrides.Scheduler != "fsrs" {
+			t.Errorf("got scheduler %q but expected fsrs", overrides.Scheduler)
+		}
+		overrides, err = SetOverride(overrides, "learning_steps", []any{"1m", "10m"})
+		if err != nil {
+			t.Fatalf("failed to set learning steps: %s", err)
+		}
+		if len(overrides.LearningSteps) != 2 || overrides.LearningSteps[1] != models.Duration(10*time.Minute) {
+			t.Errorf("got learning steps %v but expected [1m0s 10m0s]", overrides.LearningSteps)
+		}
+		if _, err := SetOverride(overrides, "interval_multipliers.extreme", 3.0); err == nil {
+			t.Errorf("expected error for unknown key")
+		}
+	})
+
+	t.Run("OverrideKeys", func(t *testing.T) {
+		overrides := &models.DeckConfig{
+			FailedReviewInterval: 2,
+			FSRS:                 &models.FSRSConfig{RequestRetention: 0.8},
+		}
+		keys, err := OverrideKeys(overrides)
+		if err != nil {
+			t.Fatalf("failed to get keys: %s", err)
+		}
+		expectedKeys := []string{"failed_review_interval", "fsrs.request_retention"}
+		if len(keys) != len(expectedKeys) {
+			t.Fatalf("got keys %v but expected %v", keys, expectedKeys)
+		}
+		for i := range keys {
+			if keys[i] != expectedKeys[i] {
+				t.Errorf("got key %q but expected %q", keys[i], expectedKeys[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Checks that the values in config make sense. Returns an error that
+// describes every problem that was found, or nil if there are none.
+// Problems are described using the keys used in config files.
+func (config *Config) Validate() error {
+	problems := []error{}
+	addProblem := func(format string, a ...any) {
+		problems = append(problems, fmt.Errorf(format, a...))
+	}
+
+	if config.DayStartHour > 23 {
+		addProblem("day_start_hour must be between 0 and 23, but is %d", config.DayStartHour)
+	}
+
+	for i, step := range config.LearningSteps {
+		if step <= 0 {
+			addProblem("learning_steps[%d] must be greater than 0", i)
+		}
+	}
+	for i, step := range config.RelearningSteps {
+		if step <= 0 {
+			addProblem("relearning_steps[%d] must be greater than 0", i)
+		}
+	}
+	if config.LearnAheadLimit < 0 {
+		addProblem("learn_ahead_limit must not be negative")
+	}
+
+	if config.FailedReviewInterval == 0 {
+		addProblem("failed_review_interval must be greater than 0")
+	}
+
+	intervals := config.SecondReviewIntervals
+	if intervals.Hard == 0 {
+		addProblem("second_review_intervals.hard must be greater than 0")
+	}
+	if intervals.Normal == 0 {
+		addProblem("second_review_intervals.normal must be greater than 0")
+	}
+	if intervals.Easy == 0 {
+		addProblem("second_review_intervals.easy must be greater than 0")
+	}
+
+	multipliers := config.IntervalMultipliers
+	if multipliers.Hard < 1.0 {
+		addProblem("interval_multipliers.hard must be at least 1.0, but is %g", multipliers.Hard)
+	}
+	if multipliers.Normal < 1.0 {
+		addProblem("interval_multipliers.normal must be at least 1.0, but is %g", multipliers.Normal)
+	}
+	if multipliers.Easy < 1.0 {
+		addProblem("interval_multipliers.easy must be at least 1.0, but is %g", multipliers.Easy)
+	}
+
+	if config.FuzzFactor < 0 || config.FuzzFactor >= 1 {
+		addProblem("fuzz_factor must be at least 0 and less than 1, but is %g", config.FuzzFactor)
+	}
+
+	if config.LoadBalance.WindowFactor < 0 || config.LoadBalance.WindowFactor >= 1 {
+		addProblem("load_balance.window_factor must be at least 0 and less than 1, but is %g", config.LoadBalance.WindowFactor)
+	}
+
+	if !slices.Contains(LeechActions, config.Leech.Action) {
+		addProblem("leech.action must be one of %s, but is %q", strings.Join(LeechActions, ", "), config.Leech.Action)
+	}
+
+	if !slices.Contains(DeckFormats, config.DeckFormat) {
+		addProblem("deck_format must be one of %s, but is %q", strings.Join(DeckFormats, ", "), config.DeckFormat)
+	}
+
+	sm2 := config.SM2
+	if sm2.MinimumEaseFactor < 1.0 {
+		addProblem("sm2.minimum_ease_factor must be at least 1.0, but is %g", sm2.MinimumEaseFactor)
+	}
+	if sm2.InitialEaseFactor < sm2.MinimumEaseFactor {
+		addProblem("sm2.initial_ease_factor must be at least sm2.minimum_ease_factor (%g), but is %g", sm2.MinimumEaseFactor, sm2.InitialEaseFactor)
+	}
+	if sm2.FirstInterval == 0 {
+		addProblem("sm2.first_interval must be greater than 0")
+	}
+	if sm2.SecondInterval == 0 {
+		addProblem("sm2.second_interval must be greater than 0")
+	}
+
+	fsrs := config.FSRS
+	if len(fsrs.Weights) != len(DefaultFSRSWeights) {
+		addProblem("fsrs.weights must contain exactly %d values, but contains %d", len(DefaultFSRSWeights), len(fsrs.Weights))
+	}
+	if fsrs.RequestRetention <= 0 || fsrs.RequestRetention >= 1 {
+		addProblem("fsrs.request_retention must be between 0 and 1, but is %g", fsrs.RequestRetention)
+	}
+	if fsrs.MaximumInterval == 0 {
+		addProblem("fsrs.maximum_interval must be greater than 0")
+	}
+
+	if len(config.Leitner.BoxIntervals) == 0 {
+		addProblem("leitner.box_intervals must contain at least one value")
+	}
+	for i, interval := range config.Leitner.BoxIntervals {
+		if interval == 0 {
+			addProblem("leitner.box_intervals[%d] must be greater than 0", i)
+		}
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,262 @@
+package deck_source
+
+import (
+	"errors"
+	"fmt"
+	"github.com/adamkpickering/clsr/internal/atomicfile"
+	"github.com/adamkpickering/clsr/internal/models"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A format that decks can be stored in, one deck per file.
+type fileFormat struct {
+	// The extension of deck files in this format, including the dot.
+	extension string
+	marshal   func(deck *models.Deck) ([]byte, error)
+	unmarshal func(contents []byte, deck *models.Deck) error
+}
+
+// Stores each deck in a file named after the deck in baseDirectory.
+// Decks are written in format, but decks in any of readFormats are
+// read, so that decks can be converted from one format to another
+// as they are written.
+type fileDeckSource struct {
+	baseDirectory string
+	format        fileFormat
+	// The formats that decks are read in, in order of preference.
+	// The first is always format.
+	readFormats []fileFormat
+	// If true, WriteDeck keeps the previous version of each deck file
+	// it overwrites, with atomicfile.BackupSuffix added to its name.
+	Backup bool
+	// The decks as they were when they were read or written, by deck
+	// name and version. When a deck is written, the snapshot of the
+	// version it was read at is used as the base for merging changes
+	// made by other processes since then.
+	snapshots map[snapshotKey]*models.Deck
+}
+
+type snapshotKey struct {
+	name    string
+	version int
+}
+
+func newFileDeckSource(baseDirectory string, format fileFormat, otherReadFormats ...fileFormat) (fileDeckSource, error) {
+	absoluteBaseDirectory, err := filepath.Abs(baseDirectory)
+	if err != nil {
+		return fileDeckSource{}, fmt.Errorf("failed to get directory %q as absolute path: %w", baseDirectory, err)
+	}
+	// check that passed base directory is valid
+	_, err = os.ReadDir(absoluteBaseDirectory)
+	if err != nil {
+		return fileDeckSource{}, fmt.Errorf("problem with base directory %q: %w", baseDirectory, err)
+	}
+
+	deckSource := fileDeckSource{
+		baseDirectory: absoluteBaseDirectory,
+		format:        format,
+		readFormats:   append([]fileFormat{format}, otherReadFormats...),
+		snapshots:     map[snapshotKey]*models.Deck{},
+	}
+	return deckSource, nil
+}
+
+func (deckSource fileDeckSource) ReadDeck(name string) (*models.Deck, error) {
+	deck, _, err := deckSource.readDeckFile(name)
+	if err != nil {
+		return &models.Deck{}, err
+	}
+	deckSource.snapshots[snapshotKey{name, deck.Version}] = deck.Copy()
+	return deck, nil
+}
+
+// Reads the file for the deck called name, preferring a file in
+// the format that decks are written in. Returns the format of the
+// file that was read.
+func (deckSource fileDeckSource) readDeckFile(name string) (*models.Deck, fileFormat, error) {
+	// find and read deck file
+	format := deckSource.format
+	for _, readFormat := range deckSource.readFormats {
+		if _, err := os.Stat(deckSource.getDeckPath(name, readFormat)); err == nil {
+			format = readFormat
+			break
+		}
+	}
+	contents, err := os.ReadFile(deckSource.getDeckPath(name, format))
+	if err != nil {
+		return &models.Deck{}, fileFormat{}, fmt.Errorf("failed to read deck: %w", err)
+	}
+
+	// decode contents into Deck struct
+	deck := &models.Deck{}
+	err = format.unmarshal(contents, deck)
+	if err != nil {
+		return &models.Deck{}, fileFormat{}, fmt.Errorf("failed to parse contents of deck: %w", err)
+	}
+
+	// do any post-parse changes to cards that are needed
+	for _, card := range deck.Cards {
+		card.Deck = deck.Name
+		sort.Stable(card.Reviews)
+		for i := range card.Reviews {
+			card.Reviews[i].Datetime = card.Reviews[i].Datetime.In(time.Local)
+		}
+	}
+
+	return deck, format, nil
+}
+
+// Writes deck to its file. Writes to the same deck directory by
+// different processes are serialized with an advisory lock. If the
+// deck was changed on disk since it was read, which is detected
+// using its Version, the changes are merged; if they conflict, an
+// error wrapping ErrConflict is returned and nothing is written.
+// If the deck was read from a file in another format, that file is
+// removed (or if Backup is set, renamed to a backup) once the deck
+// is written. Any other file for the deck in another format was not
+// read, so it is always renamed to a backup rather than removed.
+// Once the deck is written, the Version (and if changes were merged,
+// the contents) of passedDeck are updated to match what was written,
+// and its cards are no longer marked as modified.
+func (deckSource fileDeckSource) WriteDeck(passedDeck *models.Deck) error {
+	unlock, err := lockDirectory(deckSource.baseDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to lock deck directory: %w", err)
+	}
+	defer unlock()
+
+	// merge any changes made since the deck was read
+	deck := passedDeck.Copy()
+	merged := false
+	diskDeck, diskFormat, err := deckSource.readDeckFile(deck.Name)
+	existed := err == nil
+	if existed {
+		snapshot, ok := deckSource.snapshots[snapshotKey{deck.Name, deck.Version}]
+		if !ok {
+			return fmt.Errorf("deck %q already exists: %w", deck.Name, ErrConflict)
+		}
+		if diskDeck.Version != deck.Version {
+			deck, err = mergeDecks(snapshot, deck, diskDeck)
+			if err != nil {
+				return fmt.Errorf("failed to merge changes to deck %q: %w", passedDeck.Name, err)
+			}
+			merged = true
+		}
+		deck.Version = diskDeck.Version + 1
+	} else if errors.Is(err, fs.ErrNotExist) {
+		deck.Version += 1
+	} else {
+		return fmt.Errorf("failed to read current version of deck: %w", err)
+	}
+
+	// copy deck and set location of datetimes to UTC
+	utcDeck := deck.Copy()
+	for _, card := range utcDeck.Cards {
+		for i := range card.Reviews {
+			card.Reviews[i].Datetime = card.Reviews[i].Datetime.In(time.UTC)
+		}
+	}
+
+	// marshal contents of deck file
+	contents, err := deckSource.format.marshal(utcDeck)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deck: %w", err)
+	}
+
+	// write deck file
+	deckPath := deckSource.getDeckPath(deck.Name, deckSource.format)
+	err = atomicfile.WriteFile(deckPath, contents, 0644, deckSource.Backup)
+	if err != nil {
+		return fmt.Errorf("failed to write deck to file: %w", err)
+	}
+
+	// remove deck files in other formats
+	for _, format := range deckSource.readFormats[1:] {
+		oldDeckPath := deckSource.getDeckPath(deck.Name, format)
+		if _, err := os.Stat(oldDeckPath); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to stat deck file %q: %w", oldDeckPath, err)
+		}
+		wasRead := existed && format.extension == diskFormat.extension
+		if wasRead && !deckSource.Backup {
+			err = os.Remove(oldDeckPath)
+		} else {
+			err = os.Rename(oldDeckPath, oldDeckPath+atomicfile.BackupSuffix)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove deck file %q after converting it: %w", oldDeckPath, err)
+		}
+	}
+
+	deckSource.snapshots[snapshotKey{deck.Name, deck.Version}] = deck.Copy()
+	passedDeck.Version = deck.Version
+	if merged {
+		passedDeck.Active = deck.Active
+		passedDeck.Config = deck.Config
+		passedDeck.Cards = deck.Cards
+	}
+	for _, card := range passedDeck.Cards {
+		card.Modified = false
+	}
+
+	return nil
+}
+
+func (deckSource fileDeckSource) ListDecks() ([]string, error) {
+	dirEntries, err := os.ReadDir(deckSource.baseDirectory)
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to read deck directory: %w", err)
+	}
+
+	deckNames := []string{}
+	for _, dirEntry := range dirEntries {
+		// skip hidden files such as .clsr.yaml
+		nodeName := dirEntry.Name()
+		if strings.HasPrefix(nodeName, ".") {
+			continue
+		}
+		for _, format := range deckSource.readFormats {
+			if filepath.Ext(nodeName) == format.extension {
+				deckName := strings.TrimSuffix(nodeName, format.extension)
+				deckNames = append(deckNames, deckName)
+			}
+		}
+	}
+
+	// a deck may have a file in more than one format
+	slices.Sort(deckNames)
+	return slices.Compact(deckNames), nil
+}
+
+func (deckSource fileDeckSource) DeleteDeck(name string) error {
+	deleted := false
+	for _, format := range deckSource.readFormats {
+		err := os.Remove(deckSource.getDeckPath(name, format))
+		if err == nil {
+			deleted = true
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete deck: %w", err)
+		}
+	}
+	if !deleted {
+		return fmt.Errorf("failed to delete deck: %w", fs.ErrNotExist)
+	}
+	for key := range deckSource.snapshots {
+		if key.name == name {
+			delete(deckSource.snapshots, key)
+		}
+	}
+
+	return nil
+}
+
+func (deckSource fileDeckSource) getDeckPath(name string, format fileFormat) string {
+	return filepath.Join(deckSource.baseDirectory, name+format.extension)
+}
@@ -1,8 +1,14 @@
 package deck_source
 
 import (
-	"github.com/adamkpickering/clsr/internal/models"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/atomicfile"
+	"github.com/adamkpickering/clsr/internal/models"
 )
 
 func TestJSONFileDeckSource(t *testing.T) {
@@ -36,6 +42,9 @@ func TestJSONFileDeckSource(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to write initial deck: %s", err)
 		}
+		if initialDeck.IsModified() {
+			t.Errorf("expected written deck to not be modified")
+		}
 
 		// read the deck from the tempdir
 		deck, err := deckSource.ReadDeck(testDeckName)
@@ -48,4 +57,174 @@ func TestJSONFileDeckSource(t *testing.T) {
 		t.Logf("%#v", deck.Cards[0])
 		t.Logf("%#v", deck.Cards[1])
 	})
+
+	t.Run("Backup", func(t *testing.T) {
+		testDeckName := "test_deck"
+		tempDir := t.TempDir()
+		deckSource, err := NewJSONFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		deckSource.Backup = true
+		deck := models.NewDeck(testDeckName, true)
+		if err := deckSource.WriteDeck(deck); err != nil {
+			t.Fatalf("failed to write initial deck: %s", err)
+		}
+		deck.Cards = []*models.Card{models.NewCard("question", "answer", testDeckName)}
+		if err := deckSource.WriteDeck(deck); err != nil {
+			t.Fatalf("failed to write deck: %s", err)
+		}
+
+		backupPath := filepath.Join(tempDir, testDeckName+".json"+atomicfile.BackupSuffix)
+		if _, err := os.Stat(backupPath); err != nil {
+			t.Errorf("failed to stat backup: %s", err)
+		}
+		deckNames, err := deckSource.ListDecks()
+		if err != nil {
+			t.Fatalf("failed to list decks: %s", err)
+		}
+		if len(deckNames) != 1 {
+			t.Errorf("got decks %v but expected only %q", deckNames, testDeckName)
+		}
+	})
+	t.Run("ConcurrentWrites", func(t *testing.T) {
+		testDeckName := "test_deck"
+		tempDir := t.TempDir()
+		deckSource1, err := NewJSONFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		deckSource2, err := NewJSONFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		initialDeck := models.NewDeck(testDeckName, true)
+		initialDeck.Cards = []*models.Card{models.NewCard("question", "answer", testDeckName)}
+		if err := deckSource1.WriteDeck(initialDeck); err != nil {
+			t.Fatalf("failed to write initial deck: %s", err)
+		}
+
+		// change the deck in two deck sources that read it at the same time
+		deck1, err := deckSource1.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		deck2, err := deckSource2.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		now := time.Now()
+		deck1.Cards[0].Reviews = append(deck1.Cards[0].Reviews, models.NewReview(models.Normal, now))
+		deck2.Cards[0].Reviews = append(deck2.Cards[0].Reviews, models.NewReview(models.Easy, now.Add(time.Minute)))
+		if err := deckSource1.WriteDeck(deck1); err != nil {
+			t.Fatalf("failed to write first deck: %s", err)
+		}
+		if err := deckSource2.WriteDeck(deck2); err != nil {
+			t.Fatalf("failed to write second deck: %s", err)
+		}
+
+		deck, err := deckSource1.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		if length := len(deck.Cards[0].Reviews); length != 2 {
+			t.Errorf("got %d reviews but expected reviews from both writes", length)
+		}
+		if deck.Version != 3 {
+			t.Errorf("got version %d but expected 3", deck.Version)
+		}
+
+		// a conflicting change should not be written
+		deck1.Cards[0].Question = "question1"
+		deck2.Cards[0].Question = "question2"
+		if err := deckSource1.WriteDeck(deck1); err != nil {
+			t.Fatalf("failed to write first deck: %s", err)
+		}
+		if err := deckSource2.WriteDeck(deck2); !errors.Is(err, ErrConflict) {
+			t.Errorf("got error %v but expected a conflict", err)
+		}
+	})
+	t.Run("ReadAgainBeforeWrite", func(t *testing.T) {
+		testDeckName := "test_deck"
+		tempDir := t.TempDir()
+		deckSource1, err := NewJSONFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		deckSource2, err := NewJSONFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		initialDeck := models.NewDeck(testDeckName, true)
+		initialDeck.Cards = []*models.Card{models.NewCard("question", "answer", testDeckName)}
+		if err := deckSource1.WriteDeck(initialDeck); err != nil {
+			t.Fatalf("failed to write initial deck: %s", err)
+		}
+		deck1, err := deckSource1.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+
+		// another process edits a card and adds a card
+		deck2, err := deckSource2.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		deck2.Cards[0].Answer = "edited answer"
+		deck2.Cards = append(deck2.Cards, models.NewCard("question2", "answer2", testDeckName))
+		if err := deckSource2.WriteDeck(deck2); err != nil {
+			t.Fatalf("failed to write second deck: %s", err)
+		}
+
+		// the first process reads the deck again, e.g. to get deck
+		// configs, but writes the deck it read first
+		if _, err := deckSource1.ReadDeck(testDeckName); err != nil {
+			t.Fatalf("failed to read deck again: %s", err)
+		}
+		deck1.Cards[0].Reviews = append(deck1.Cards[0].Reviews, models.NewReview(models.Normal, time.Now()))
+		if err := deckSource1.WriteDeck(deck1); err != nil {
+			t.Fatalf("failed to write first deck: %s", err)
+		}
+
+		deck, err := deckSource1.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		if length := len(deck.Cards); length != 2 {
+			t.Fatalf("got %d cards but expected the added card to be kept", length)
+		}
+		if answer := deck.Cards[0].Answer; answer != "edited answer" {
+			t.Errorf("got answer %q but expected the edited answer to be kept", answer)
+		}
+		if length := len(deck.Cards[0].Reviews); length != 1 {
+			t.Errorf("got %d reviews but expected 1", length)
+		}
+	})
+
+	t.Run("IgnoresYAMLFiles", func(t *testing.T) {
+		tempDir := t.TempDir()
+		contents, err := os.ReadFile(filepath.Join("testdata", "test_deck.json"))
+		if err != nil {
+			t.Fatalf("failed to read test deck: %s", err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, "test_deck.json"), contents, 0644); err != nil {
+			t.Fatalf("failed to write test deck: %s", err)
+		}
+		// a YAML file that is not a deck
+		if err := os.WriteFile(filepath.Join(tempDir, "notes.yaml"), []byte("- not a deck\n"), 0644); err != nil {
+			t.Fatalf("failed to write YAML file: %s", err)
+		}
+		deckSource, err := NewJSONFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+
+		deckNames, err := deckSource.ListDecks()
+		if err != nil {
+			t.Fatalf("failed to list decks: %s", err)
+		}
+		if len(deckNames) != 1 || deckNames[0] != "test_deck" {
+			t.Errorf("got decks %v but expected only %q", deckNames, "test_deck")
+		}
+	})
 }
@@ -0,0 +1,34 @@
+package deck_source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// The name of the file in a deck directory that is locked while
+// decks in that directory are written.
+const lockFileName = ".clsr.lock"
+
+// Takes an advisory lock on directory, waiting until any other process
+// that holds the lock releases it. The lock is released by calling
+// the returned function.
+func lockDirectory(directory string) (func() error, error) {
+	lockPath := filepath.Join(directory, lockFileName)
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %q: %w", lockPath, err)
+	}
+	unlock := func() error {
+		if err := unlockFile(file); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to unlock %q: %w", lockPath, err)
+		}
+		return file.Close()
+	}
+	return unlock, nil
+}
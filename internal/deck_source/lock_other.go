@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package deck_source
+
+import "os"
+
+// Advisory locks are not supported on this platform, so decks are
+// written without them.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
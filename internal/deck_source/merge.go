@@ -0,0 +1,176 @@
+package deck_source
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// Returned (wrapped) when a deck cannot be written because it was
+// changed by another process in a way that conflicts with the
+// changes being written.
+var ErrConflict = errors.New("deck was changed by another process")
+
+// Does a three-way merge of a deck. base is the deck as it was read,
+// ours is the deck with the changes that are being written, and
+// theirs is the deck as it is now on disk. Reviews from both sides
+// are kept. Any other value that was changed on only one side takes
+// that side's value. Returns an error wrapping ErrConflict if a value
+// was changed differently on both sides, or if a card was deleted on
+// one side and changed on the other.
+func mergeDecks(base, ours, theirs *models.Deck) (*models.Deck, error) {
+	merged := theirs.Copy()
+	var ok bool
+	merged.Active, ok = mergeValue(base.Active, ours.Active, theirs.Active, isEqual[bool])
+	if !ok {
+		return nil, fmt.Errorf("active state of deck was changed on both sides: %w", ErrConflict)
+	}
+	merged.Config, ok = mergeValue(base.Config, ours.Config, theirs.Config, func(a, b *models.DeckConfig) bool {
+		return reflect.DeepEqual(a, b)
+	})
+	if !ok {
+		return nil, fmt.Errorf("config of deck was changed on both sides: %w", ErrConflict)
+	}
+
+	baseCards := getCardsByID(base.Cards)
+	ourCards := getCardsByID(ours.Cards)
+	theirCards := getCardsByID(theirs.Cards)
+	merged.Cards = make([]*models.Card, 0, len(theirs.Cards))
+	for _, theirCard := range theirs.Cards {
+		baseCard, inBase := baseCards[theirCard.ID]
+		ourCard, inOurs := ourCards[theirCard.ID]
+		switch {
+		case inOurs:
+			if !inBase {
+				// added on both sides, so treat it as empty in base
+				baseCard = &models.Card{ID: theirCard.ID}
+			}
+			mergedCard, err := mergeCards(baseCard, ourCard, theirCard)
+			if err != nil {
+				return nil, err
+			}
+			merged.Cards = append(merged.Cards, mergedCard)
+		case inBase:
+			if !cardsEqual(baseCard, theirCard) {
+				return nil, fmt.Errorf("card %q was deleted but changed by another process: %w", theirCard.ID, ErrConflict)
+			}
+		default:
+			merged.Cards = append(merged.Cards, theirCard.Copy())
+		}
+	}
+	for _, ourCard := range ours.Cards {
+		if _, inTheirs := theirCards[ourCard.ID]; inTheirs {
+			continue
+		}
+		baseCard, inBase := baseCards[ourCard.ID]
+		if !inBase {
+			merged.Cards = append(merged.Cards, ourCard.Copy())
+		} else if !cardsEqual(baseCard, ourCard) {
+			return nil, fmt.Errorf("card %q was changed but deleted by another process: %w", ourCard.ID, ErrConflict)
+		}
+	}
+
+	return merged, nil
+}
+
+// Does a three-way merge of a card in the same way as mergeDecks.
+func mergeCards(base, ours, theirs *models.Card) (*models.Card, error) {
+	merged := theirs.Copy()
+	merged.Modified = ours.Modified || theirs.Modified
+	var questionOK, answerOK, activeOK, tagsOK bool
+	merged.Question, questionOK = mergeValue(base.Question, ours.Question, theirs.Question, isEqual[string])
+	merged.Answer, answerOK = mergeValue(base.Answer, ours.Answer, theirs.Answer, isEqual[string])
+	merged.Active, activeOK = mergeValue(base.Active, ours.Active, theirs.Active, isEqual[bool])
+	merged.Tags, tagsOK = mergeValue(base.Tags, ours.Tags, theirs.Tags, slices.Equal[[]string])
+	if !questionOK || !answerOK || !activeOK || !tagsOK {
+		return nil, fmt.Errorf("card %q was changed on both sides: %w", theirs.ID, ErrConflict)
+	}
+	merged.Tags = slices.Clone(merged.Tags)
+
+	merged.Reviews = slices.Clone(theirs.Reviews)
+	for _, review := range ours.Reviews {
+		if !containsReview(theirs.Reviews, review) {
+			merged.Reviews = append(merged.Reviews, review)
+		}
+	}
+	sort.Stable(merged.Reviews)
+
+	return merged, nil
+}
+
+// Adds the reviews of the cards in from to the cards with the same
+// IDs in deck, skipping reviews that deck already has. Cards in from
+// that are not in deck are ignored, as are all changes to cards other
+// than reviews. Cards that reviews are added to are marked as modified.
+// Returns the number of reviews that were added.
+func MergeReviews(deck, from *models.Deck) int {
+	cards := getCardsByID(deck.Cards)
+	added := 0
+	for _, fromCard := range from.Cards {
+		card, ok := cards[fromCard.ID]
+		if !ok {
+			continue
+		}
+		for _, review := range fromCard.Reviews {
+			if !containsReview(card.Reviews, review) {
+				card.Reviews = append(card.Reviews, review)
+				card.Modified = true
+				added += 1
+			}
+		}
+		sort.Stable(card.Reviews)
+	}
+	return added
+}
+
+// Returns the value that results from merging the changes made to
+// base in ours and theirs. Returns false if both ours and theirs
+// changed base, and did so differently.
+func mergeValue[T any](base, ours, theirs T, equal func(T, T) bool) (T, bool) {
+	switch {
+	case equal(ours, base):
+		return theirs, true
+	case equal(theirs, base), equal(ours, theirs):
+		return ours, true
+	default:
+		return ours, false
+	}
+}
+
+func isEqual[T comparable](a, b T) bool {
+	return a == b
+}
+
+func getCardsByID(cards []*models.Card) map[string]*models.Card {
+	cardsByID := make(map[string]*models.Card, len(cards))
+	for _, card := range cards {
+		cardsByID[card.ID] = card
+	}
+	return cardsByID
+}
+
+// Tells the caller whether a and b have the same contents and reviews.
+func cardsEqual(a, b *models.Card) bool {
+	if a.Question != b.Question || a.Answer != b.Answer || a.Active != b.Active || !slices.Equal(a.Tags, b.Tags) {
+		return false
+	}
+	if len(a.Reviews) != len(b.Reviews) {
+		return false
+	}
+	for _, review := range a.Reviews {
+		if !containsReview(b.Reviews, review) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsReview(reviews models.ReviewSlice, review models.Review) bool {
+	return slices.ContainsFunc(reviews, func(other models.Review) bool {
+		return other.Result == review.Result && other.Datetime.Equal(review.Datetime)
+	})
+}
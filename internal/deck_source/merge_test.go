@@ -0,0 +1,137 @@
+package deck_source
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestMergeDecks(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newBase := func() *models.Deck {
+		deck := models.NewDeck("test_deck", true)
+		deck.Cards = []*models.Card{
+			{ID: "card1", Active: true, Question: "question1", Answer: "answer1", Reviews: models.ReviewSlice{}},
+			{ID: "card2", Active: true, Question: "question2", Answer: "answer2", Reviews: models.ReviewSlice{}},
+		}
+		return deck
+	}
+
+	t.Run("ReviewsAndEdits", func(t *testing.T) {
+		base := newBase()
+		ours := base.Copy()
+		ours.Cards[0].Reviews = append(ours.Cards[0].Reviews, models.NewReview(models.Normal, now))
+		theirs := base.Copy()
+		theirs.Cards[0].Reviews = append(theirs.Cards[0].Reviews, models.NewReview(models.Failed, now.Add(time.Hour)))
+		theirs.Cards[0].Answer = "edited answer"
+		theirs.Cards[1].Active = false
+
+		merged, err := mergeDecks(base, ours, theirs)
+		if err != nil {
+			t.Fatalf("failed to merge decks: %s", err)
+		}
+		if length := len(merged.Cards[0].Reviews); length != 2 {
+			t.Errorf("got %d reviews but expected 2", length)
+		} else if merged.Cards[0].Reviews[0].Result != models.Failed {
+			t.Errorf("expected reviews to be sorted with most recent first")
+		}
+		if answer := merged.Cards[0].Answer; answer != "edited answer" {
+			t.Errorf("got answer %q but expected the edited answer", answer)
+		}
+		if merged.Cards[1].Active {
+			t.Errorf("expected card2 to be inactive")
+		}
+	})
+
+	t.Run("AddedAndDeletedCards", func(t *testing.T) {
+		base := newBase()
+		ours := base.Copy()
+		ours.Cards = append(ours.Cards[1:], models.NewCard("question3", "answer3", "test_deck"))
+		theirs := base.Copy()
+		theirs.Cards = append(theirs.Cards, models.NewCard("question4", "answer4", "test_deck"))
+
+		merged, err := mergeDecks(base, ours, theirs)
+		if err != nil {
+			t.Fatalf("failed to merge decks: %s", err)
+		}
+		questions := []string{}
+		for _, card := range merged.Cards {
+			questions = append(questions, card.Question)
+		}
+		expected := []string{"question2", "question4", "question3"}
+		if len(questions) != len(expected) {
+			t.Fatalf("got cards with questions %v but expected %v", questions, expected)
+		}
+		for i := range expected {
+			if questions[i] != expected[i] {
+				t.Errorf("got cards with questions %v but expected %v", questions, expected)
+				break
+			}
+		}
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		testCases := []struct {
+			Name   string
+			Ours   func(deck *models.Deck)
+			Theirs func(deck *models.Deck)
+		}{
+			{
+				Name:   "SameField",
+				Ours:   func(deck *models.Deck) { deck.Cards[0].Question = "ours" },
+				Theirs: func(deck *models.Deck) { deck.Cards[0].Question = "theirs" },
+			},
+			{
+				Name: "DeletedAndReviewed",
+				Ours: func(deck *models.Deck) { deck.Cards = deck.Cards[1:] },
+				Theirs: func(deck *models.Deck) {
+					deck.Cards[0].Reviews = append(deck.Cards[0].Reviews, models.NewReview(models.Easy, now))
+				},
+			},
+			{
+				Name:   "ChangedAndDeleted",
+				Ours:   func(deck *models.Deck) { deck.Cards[0].Answer = "ours" },
+				Theirs: func(deck *models.Deck) { deck.Cards = deck.Cards[1:] },
+			},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.Name, func(t *testing.T) {
+				base := newBase()
+				ours := base.Copy()
+				testCase.Ours(ours)
+				theirs := base.Copy()
+				testCase.Theirs(theirs)
+				_, err := mergeDecks(base, ours, theirs)
+				if !errors.Is(err, ErrConflict) {
+					t.Errorf("got error %v but expected a conflict", err)
+				}
+			})
+		}
+	})
+}
+
+func TestMergeReviews(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	deck := models.NewDeck("test_deck", true)
+	card := &models.Card{ID: "card1", Question: "edited", Reviews: models.ReviewSlice{models.NewReview(models.Normal, now)}}
+	deck.Cards = []*models.Card{card}
+	from := deck.Copy()
+	from.Cards[0].Question = "question"
+	from.Cards[0].Reviews = append(models.ReviewSlice{models.NewReview(models.Failed, now.Add(time.Hour))}, from.Cards[0].Reviews...)
+	from.Cards = append(from.Cards, &models.Card{ID: "card2", Reviews: models.ReviewSlice{models.NewReview(models.Easy, now)}})
+
+	if added := MergeReviews(deck, from); added != 1 {
+		t.Errorf("added %d reviews but expected 1", added)
+	}
+	if len(deck.Cards) != 1 || card.Question != "edited" {
+		t.Errorf("expected only reviews to be merged")
+	}
+	if len(card.Reviews) != 2 || card.Reviews[0].Result != models.Failed {
+		t.Errorf("got reviews %v but expected the failed review to be added first", card.Reviews)
+	}
+	if !card.Modified {
+		t.Errorf("expected card to be marked as modified")
+	}
+}
@@ -0,0 +1,43 @@
+package deck_source
+
+import (
+	"bytes"
+	"github.com/adamkpickering/clsr/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlFormat = fileFormat{
+	extension: ".yaml",
+	marshal: func(deck *models.Deck) ([]byte, error) {
+		buffer := &bytes.Buffer{}
+		encoder := yaml.NewEncoder(buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(deck); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	},
+	unmarshal: func(contents []byte, deck *models.Deck) error {
+		return yaml.Unmarshal(contents, deck)
+	},
+}
+
+// Stores each deck as a YAML file in a directory. Questions and
+// answers that span multiple lines are written as block scalars,
+// which makes them easier to edit by hand than JSON strings. Decks
+// that are stored as JSON files are also read, and are converted to
+// YAML when they are written.
+type YAMLFileDeckSource struct {
+	fileDeckSource
+}
+
+func NewYAMLFileDeckSource(baseDirectory string) (YAMLFileDeckSource, error) {
+	deckSource, err := newFileDeckSource(baseDirectory, yamlFormat, jsonFormat)
+	if err != nil {
+		return YAMLFileDeckSource{}, err
+	}
+	return YAMLFileDeckSource{deckSource}, nil
+}
@@ -0,0 +1,143 @@
+package deck_source
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/atomicfile"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestYAMLFileDeckSource(t *testing.T) {
+	t.Run("WriteDeck", func(t *testing.T) {
+		testDeckName := "test_deck"
+		tempDir := t.TempDir()
+		deckSource, err := NewYAMLFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		deck := models.NewDeck(testDeckName, true)
+		card := models.NewCard("What does this print?\n\n    fmt.Println(1)", "1", testDeckName)
+		card.Reviews = append(card.Reviews, models.NewReview(models.Normal, time.Now()))
+		deck.Cards = []*models.Card{card}
+		if err := deckSource.WriteDeck(deck); err != nil {
+			t.Fatalf("failed to write deck: %s", err)
+		}
+
+		contents, err := os.ReadFile(filepath.Join(tempDir, testDeckName+".yaml"))
+		if err != nil {
+			t.Fatalf("failed to read deck file: %s", err)
+		}
+		if !strings.Contains(string(contents), "question: |-\n") {
+			t.Errorf("expected multi-line question to be a block scalar in:\n%s", contents)
+		}
+
+		readDeck, err := deckSource.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		if length := len(readDeck.Cards); length != 1 {
+			t.Fatalf("read %d, not 1, cards for deck", length)
+		}
+		readCard := readDeck.Cards[0]
+		if readCard.Question != card.Question {
+			t.Errorf("got question %q but expected %q", readCard.Question, card.Question)
+		}
+		if length := len(readCard.Reviews); length != 1 {
+			t.Errorf("read %d, not 1, reviews for card", length)
+		} else if !readCard.Reviews[0].Datetime.Equal(card.Reviews[0].Datetime) {
+			t.Errorf("got review time %s but expected %s", readCard.Reviews[0].Datetime, card.Reviews[0].Datetime)
+		}
+	})
+
+	t.Run("ConvertsJSONDecks", func(t *testing.T) {
+		testDeckName := "test_deck"
+		tempDir := t.TempDir()
+		contents, err := os.ReadFile(filepath.Join("testdata", testDeckName+".json"))
+		if err != nil {
+			t.Fatalf("failed to read test deck: %s", err)
+		}
+		jsonPath := filepath.Join(tempDir, testDeckName+".json")
+		if err := os.WriteFile(jsonPath, contents, 0644); err != nil {
+			t.Fatalf("failed to write test deck: %s", err)
+		}
+		// the config file in the data directory should not be listed
+		if err := os.WriteFile(filepath.Join(tempDir, ".clsr.yaml"), []byte{}, 0644); err != nil {
+			t.Fatalf("failed to write config file: %s", err)
+		}
+		deckSource, err := NewYAMLFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+
+		deckNames, err := deckSource.ListDecks()
+		if err != nil {
+			t.Fatalf("failed to list decks: %s", err)
+		}
+		if len(deckNames) != 1 || deckNames[0] != testDeckName {
+			t.Errorf("got decks %v but expected only %q", deckNames, testDeckName)
+		}
+		deck, err := deckSource.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read JSON deck: %s", err)
+		}
+		if err := deckSource.WriteDeck(deck); err != nil {
+			t.Fatalf("failed to write deck: %s", err)
+		}
+
+		if _, err := os.Stat(jsonPath); err == nil {
+			t.Errorf("expected JSON deck file to be removed")
+		}
+		readDeck, err := deckSource.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read converted deck: %s", err)
+		}
+		if length := len(readDeck.Cards); length != 2 {
+			t.Errorf("read %d, not 2, cards for converted deck", length)
+		}
+	})
+
+	t.Run("BacksUpUnreadJSONDecks", func(t *testing.T) {
+		testDeckName := "test_deck"
+		tempDir := t.TempDir()
+		deckSource, err := NewYAMLFileDeckSource(tempDir)
+		if err != nil {
+			t.Fatalf("failed to create deck source: %s", err)
+		}
+		if err := deckSource.WriteDeck(models.NewDeck(testDeckName, true)); err != nil {
+			t.Fatalf("failed to write deck: %s", err)
+		}
+		// a JSON file for the same deck, which is not read because the
+		// YAML file is preferred
+		contents, err := os.ReadFile(filepath.Join("testdata", testDeckName+".json"))
+		if err != nil {
+			t.Fatalf("failed to read test deck: %s", err)
+		}
+		jsonPath := filepath.Join(tempDir, testDeckName+".json")
+		if err := os.WriteFile(jsonPath, contents, 0644); err != nil {
+			t.Fatalf("failed to write test deck: %s", err)
+		}
+
+		deck, err := deckSource.ReadDeck(testDeckName)
+		if err != nil {
+			t.Fatalf("failed to read deck: %s", err)
+		}
+		if err := deckSource.WriteDeck(deck); err != nil {
+			t.Fatalf("failed to write deck: %s", err)
+		}
+
+		if _, err := os.Stat(jsonPath); err == nil {
+			t.Errorf("expected JSON deck file to be removed")
+		}
+		backupContents, err := os.ReadFile(jsonPath + atomicfile.BackupSuffix)
+		if err != nil {
+			t.Fatalf("expected JSON deck file to be kept as a backup: %s", err)
+		}
+		if string(backupContents) != string(contents) {
+			t.Errorf("expected backup to have the contents of the JSON deck file")
+		}
+	})
+}
@@ -3,17 +3,23 @@ package models
 import (
 	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
 )
 
+// The tag that is added to cards that have become leeches.
+const LeechTag = "leech"
+
 type Card struct {
-	ID       string      `json:"id"`
-	Deck     string      `json:"-"`
-	Version  int         `json:"version"`
-	Active   bool        `json:"active"`
-	Modified bool        `json:"-"`
-	Question string      `json:"question"`
-	Answer   string      `json:"answer"`
-	Reviews  ReviewSlice `json:"reviews"`
+	ID       string      `json:"id" yaml:"id"`
+	Deck     string      `json:"-" yaml:"-"`
+	Version  int         `json:"version" yaml:"version"`
+	Active   bool        `json:"active" yaml:"active"`
+	Modified bool        `json:"-" yaml:"-"`
+	Question string      `json:"question" yaml:"question"`
+	Answer   string      `json:"answer" yaml:"answer"`
+	Tags     []string    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Reviews  ReviewSlice `json:"reviews" yaml:"reviews"`
 }
 
 // Returns a string of length n that is comprised of random letters
@@ -46,9 +52,51 @@ func (card *Card) Copy() *Card {
 	newReviews := make(ReviewSlice, len(card.Reviews))
 	copy(newReviews, card.Reviews)
 	newCard.Reviews = newReviews
+	newCard.Tags = slices.Clone(card.Tags)
 	return &newCard
 }
 
+// Returns a copy of the reviews of card, sorted from most recent to
+// least recent.
+func (card *Card) SortedReviews() ReviewSlice {
+	reviews := make(ReviewSlice, len(card.Reviews))
+	copy(reviews, card.Reviews)
+	sort.Stable(reviews)
+	return reviews
+}
+
+// Returns the number of times that a review of card has been failed.
+func (card *Card) LapseCount() int {
+	count := 0
+	for _, review := range card.Reviews {
+		if review.Result == Failed {
+			count += 1
+		}
+	}
+	return count
+}
+
+// Tells the caller whether card has been failed at least threshold
+// times. A threshold of 0 means that no card is a leech.
+func (card *Card) IsLeech(threshold uint) bool {
+	return threshold > 0 && card.LapseCount() >= int(threshold)
+}
+
+func (card *Card) HasTag(tag string) bool {
+	return slices.Contains(card.Tags, tag)
+}
+
+// Adds tag to card if card does not already have it. Returns true
+// if the tag was added.
+func (card *Card) AddTag(tag string) bool {
+	if card.HasTag(tag) {
+		return false
+	}
+	card.Tags = append(card.Tags, tag)
+	card.Modified = true
+	return true
+}
+
 func (card *Card) String() string {
 	return fmt.Sprintf("%s\n%s%s\n", card.Question, tempFileDivider, card.Answer)
 }
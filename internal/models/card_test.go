@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 )
 
 func TestCard(t *testing.T) {
@@ -10,7 +11,7 @@ func TestCard(t *testing.T) {
 		oldAnswer := "Old answer"
 		deckName := "test_deck"
 		oldCard := NewCard(oldQuestion, oldAnswer, deckName)
-		oldCard.Reviews = append(oldCard.Reviews, NewReview(Hard))
+		oldCard.Reviews = append(oldCard.Reviews, NewReview(Hard, time.Now()))
 
 		// get a new card with different field values
 		newCard := oldCard.Copy()
@@ -19,7 +20,7 @@ func TestCard(t *testing.T) {
 		newCard.Question = "New question"
 		newCard.Answer = "New answer"
 		newCard.Reviews[0].Result = Normal
-		newCard.Reviews = append(newCard.Reviews, NewReview(Easy))
+		newCard.Reviews = append(newCard.Reviews, NewReview(Easy, time.Now()))
 
 		// check that the fields of oldCard have not been changed
 		if newCard.ID == oldCard.ID {
@@ -38,4 +39,52 @@ func TestCard(t *testing.T) {
 			t.Errorf("len(newCard.Reviews) matches len(oldCard.Reviews)")
 		}
 	})
+	t.Run("SortedReviews", func(t *testing.T) {
+		card := NewCard("question", "answer", "test_deck")
+		now := time.Now()
+		card.Reviews = ReviewSlice{NewReview(Failed, now.Add(-time.Hour)), NewReview(Easy, now)}
+		reviews := card.SortedReviews()
+		if reviews[0].Result != Easy {
+			t.Errorf("expected most recent review first")
+		}
+		if card.Reviews[0].Result != Failed {
+			t.Errorf("expected reviews of card to be unchanged")
+		}
+	})
+
+	t.Run("LapseCount", func(t *testing.T) {
+		card := NewCard("question", "answer", "test_deck")
+		for _, result := range []ReviewResult{Failed, Normal, Failed, Hard, Failed} {
+			card.Reviews = append(card.Reviews, NewReview(result, time.Now()))
+		}
+		if count := card.LapseCount(); count != 3 {
+			t.Errorf("got lapse count %d but expected 3", count)
+		}
+		if !card.IsLeech(3) {
+			t.Errorf("expected card to be a leech with threshold 3")
+		}
+		if card.IsLeech(4) {
+			t.Errorf("expected card not to be a leech with threshold 4")
+		}
+		if card.IsLeech(0) {
+			t.Errorf("expected card not to be a leech with threshold 0")
+		}
+	})
+
+	t.Run("AddTag", func(t *testing.T) {
+		card := NewCard("question", "answer", "test_deck")
+		card.Modified = false
+		if !card.AddTag(LeechTag) {
+			t.Errorf("expected tag to be added")
+		}
+		if !card.Modified {
+			t.Errorf("expected card to be modified")
+		}
+		if card.AddTag(LeechTag) {
+			t.Errorf("expected tag not to be added a second time")
+		}
+		if len(card.Tags) != 1 || !card.HasTag(LeechTag) {
+			t.Errorf("got tags %v but expected [%s]", card.Tags, LeechTag)
+		}
+	})
 }
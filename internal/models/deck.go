@@ -2,10 +2,15 @@ package models
 
 // A Deck is a collection of Cards that are all related.
 type Deck struct {
-	Name    string  `json:"name"`
-	Version int     `json:"version"`
-	Active  bool    `json:"active"`
-	Cards   []*Card `json:"cards"`
+	Name string `json:"name" yaml:"name"`
+	// Increased every time the deck is written, so that changes made
+	// by other processes since the deck was read can be detected.
+	Version int  `json:"version" yaml:"version"`
+	Active  bool `json:"active" yaml:"active"`
+	// Config values that apply only to the cards in this deck.
+	// May be nil.
+	Config *DeckConfig `json:"config,omitempty" yaml:"config,omitempty"`
+	Cards  []*Card     `json:"cards" yaml:"cards"`
 }
 
 func NewDeck(name string, active bool) *Deck {
@@ -20,9 +25,22 @@ func NewDeck(name string, active bool) *Deck {
 
 func (deck *Deck) Copy() *Deck {
 	copiedDeck := NewDeck(deck.Name, deck.Active)
+	copiedDeck.Version = deck.Version
+	copiedDeck.Config = deck.Config.Copy()
 	copiedDeck.Cards = make([]*Card, 0, len(deck.Cards))
 	for _, card := range deck.Cards {
 		copiedDeck.Cards = append(copiedDeck.Cards, card.Copy())
 	}
 	return copiedDeck
 }
+
+// Tells the caller whether any of the cards in deck have been
+// modified since deck was read or last written.
+func (deck *Deck) IsModified() bool {
+	for _, card := range deck.Cards {
+		if card.Modified {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// DeckConfig holds config values that apply to the cards of a single
+// deck. Any value that is unset (zero or nil) keeps the value from the
+// config that the DeckConfig is applied to. Only values that affect
+// how the cards of a deck are scheduled can be set; the others
+// (day_start_hour, learn_ahead_limit, load_balance, leech, backup_decks
+// and deck_format) apply to every deck and can only be set in a config
+// file. Each value has the same meaning as the config value with the
+// same key.
+type DeckConfig struct {
+	Scheduler             string                 `json:"scheduler,omitempty" yaml:"scheduler,omitempty"`
+	LearningSteps         []Duration             `json:"learning_steps,omitempty" yaml:"learning_steps,omitempty"`
+	RelearningSteps       []Duration             `json:"relearning_steps,omitempty" yaml:"relearning_steps,omitempty"`
+	FailedReviewInterval  uint                   `json:"failed_review_interval,omitempty" yaml:"failed_review_interval,omitempty"`
+	SecondReviewIntervals *SecondReviewIntervals `json:"second_review_intervals,omitempty" yaml:"second_review_intervals,omitempty"`
+	IntervalMultipliers   *IntervalMultipliers   `json:"interval_multipliers,omitempty" yaml:"interval_multipliers,omitempty"`
+	SM2                   *SM2Config             `json:"sm2,omitempty" yaml:"sm2,omitempty"`
+	FSRS                  *FSRSConfig            `json:"fsrs,omitempty" yaml:"fsrs,omitempty"`
+	FuzzFactor            float64                `json:"fuzz_factor,omitempty" yaml:"fuzz_factor,omitempty"`
+	Leitner               *LeitnerConfig         `json:"leitner,omitempty" yaml:"leitner,omitempty"`
+}
+
+// The per-deck values of the second_review_intervals config section.
+type SecondReviewIntervals struct {
+	Hard   uint `json:"hard,omitempty" yaml:"hard,omitempty"`
+	Normal uint `json:"normal,omitempty" yaml:"normal,omitempty"`
+	Easy   uint `json:"easy,omitempty" yaml:"easy,omitempty"`
+}
+
+// The per-deck values of the interval_multipliers config section.
+type IntervalMultipliers struct {
+	Hard   float64 `json:"hard,omitempty" yaml:"hard,omitempty"`
+	Normal float64 `json:"normal,omitempty" yaml:"normal,omitempty"`
+	Easy   float64 `json:"easy,omitempty" yaml:"easy,omitempty"`
+}
+
+// The per-deck values of the sm2 config section.
+type SM2Config struct {
+	InitialEaseFactor float64 `json:"initial_ease_factor,omitempty" yaml:"initial_ease_factor,omitempty"`
+	MinimumEaseFactor float64 `json:"minimum_ease_factor,omitempty" yaml:"minimum_ease_factor,omitempty"`
+	FirstInterval     uint    `json:"first_interval,omitempty" yaml:"first_interval,omitempty"`
+	SecondInterval    uint    `json:"second_interval,omitempty" yaml:"second_interval,omitempty"`
+}
+
+// The per-deck values of the fsrs config section.
+type FSRSConfig struct {
+	Weights          []float64 `json:"weights,omitempty" yaml:"weights,omitempty"`
+	RequestRetention float64   `json:"request_retention,omitempty" yaml:"request_retention,omitempty"`
+	MaximumInterval  uint      `json:"maximum_interval,omitempty" yaml:"maximum_interval,omitempty"`
+}
+
+// The per-deck values of the leitner config section.
+type LeitnerConfig struct {
+	BoxIntervals []uint `json:"box_intervals,omitempty" yaml:"box_intervals,omitempty"`
+}
+
+// Returns a deep copy of deckConfig. Returns nil if deckConfig is nil.
+func (deckConfig *DeckConfig) Copy() *DeckConfig {
+	if deckConfig == nil {
+		return nil
+	}
+	newDeckConfig := *deckConfig
+	newDeckConfig.LearningSteps = slices.Clone(deckConfig.LearningSteps)
+	newDeckConfig.RelearningSteps = slices.Clone(deckConfig.RelearningSteps)
+	if deckConfig.SecondReviewIntervals != nil {
+		intervals := *deckConfig.SecondReviewIntervals
+		newDeckConfig.SecondReviewIntervals = &intervals
+	}
+	if deckConfig.IntervalMultipliers != nil {
+		multipliers := *deckConfig.IntervalMultipliers
+		newDeckConfig.IntervalMultipliers = &multipliers
+	}
+	if deckConfig.SM2 != nil {
+		sm2 := *deckConfig.SM2
+		newDeckConfig.SM2 = &sm2
+	}
+	if deckConfig.FSRS != nil {
+		fsrs := *deckConfig.FSRS
+		fsrs.Weights = slices.Clone(deckConfig.FSRS.Weights)
+		newDeckConfig.FSRS = &fsrs
+	}
+	if deckConfig.Leitner != nil {
+		leitner := *deckConfig.Leitner
+		leitner.BoxIntervals = slices.Clone(deckConfig.Leitner.BoxIntervals)
+		newDeckConfig.Leitner = &leitner
+	}
+	return &newDeckConfig
+}
+
+// A time.Duration that is written as a string such as "10m0s" in JSON
+// as well as in YAML, so that durations in deck files are readable.
+type Duration time.Duration
+
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(duration).String())
+}
+
+func (duration *Duration) UnmarshalJSON(contents []byte) error {
+	var rawDuration string
+	if err := json.Unmarshal(contents, &rawDuration); err != nil {
+		return err
+	}
+	parsedDuration, err := time.ParseDuration(rawDuration)
+	if err != nil {
+		return err
+	}
+	*duration = Duration(parsedDuration)
+	return nil
+}
+
+func (duration Duration) MarshalYAML() (any, error) {
+	return time.Duration(duration).String(), nil
+}
+
+func (duration *Duration) UnmarshalYAML(unmarshal func(any) error) error {
+	var rawDuration string
+	if err := unmarshal(&rawDuration); err != nil {
+		return err
+	}
+	parsedDuration, err := time.ParseDuration(rawDuration)
+	if err != nil {
+		return err
+	}
+	*duration = Duration(parsedDuration)
+	return nil
+}
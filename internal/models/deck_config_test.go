@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDeckConfig(t *testing.T) {
+	t.Run("Copy", func(t *testing.T) {
+		deckConfig := &DeckConfig{
+			LearningSteps: []Duration{Duration(time.Minute)},
+			FSRS:          &FSRSConfig{Weights: []float64{1, 2}},
+		}
+		newDeckConfig := deckConfig.Copy()
+		newDeckConfig.LearningSteps[0] = Duration(time.Hour)
+		newDeckConfig.FSRS.Weights[0] = 3
+		newDeckConfig.FSRS.RequestRetention = 0.5
+		if deckConfig.LearningSteps[0] != Duration(time.Minute) || deckConfig.FSRS.Weights[0] != 1 || deckConfig.FSRS.RequestRetention != 0 {
+			t.Errorf("changing the copy changed the original deck config")
+		}
+		if (*DeckConfig)(nil).Copy() != nil {
+			t.Errorf("expected copy of nil deck config to be nil")
+		}
+	})
+}
+
+func TestDuration(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		contents, err := json.Marshal([]Duration{Duration(90 * time.Second)})
+		if err != nil {
+			t.Fatalf("failed to marshal durations: %s", err)
+		}
+		if string(contents) != `["1m30s"]` {
+			t.Errorf("got %s but expected [\"1m30s\"]", contents)
+		}
+		durations := []Duration{}
+		if err := json.Unmarshal(contents, &durations); err != nil {
+			t.Fatalf("failed to unmarshal durations: %s", err)
+		}
+		if len(durations) != 1 || durations[0] != Duration(90*time.Second) {
+			t.Errorf("got durations %v but expected [1m30s]", durations)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		contents, err := yaml.Marshal([]Duration{Duration(90 * time.Second)})
+		if err != nil {
+			t.Fatalf("failed to marshal durations: %s", err)
+		}
+		durations := []Duration{}
+		if err := yaml.Unmarshal(contents, &durations); err != nil {
+			t.Fatalf("failed to unmarshal durations: %s", err)
+		}
+		if len(durations) != 1 || durations[0] != Duration(90*time.Second) {
+			t.Errorf("got durations %v from %q but expected [1m30s]", durations, contents)
+		}
+	})
+}
@@ -15,6 +15,20 @@ func TestDeck(t *testing.T) {
 		}
 	})
 
+	t.Run("IsModified", func(t *testing.T) {
+		deck := NewDeck("test_deck", true)
+		card := NewCard("question", "answer", deck.Name)
+		card.Modified = false
+		deck.Cards = append(deck.Cards, card)
+		if deck.IsModified() {
+			t.Errorf("expected deck with no modified cards to not be modified")
+		}
+		card.AddTag(LeechTag)
+		if !deck.IsModified() {
+			t.Errorf("expected deck with modified card to be modified")
+		}
+	})
+
 	t.Run("JSONMarshal", func(t *testing.T) {
 		deckName := "test_deck"
 		deck := NewDeck(deckName, true)
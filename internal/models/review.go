@@ -14,18 +14,18 @@ const (
 )
 
 type Review struct {
-	Version  int          `json:"version"`
-	Result   ReviewResult `json:"result"`
-	Datetime time.Time    `json:"datetime"`
+	Version  int          `json:"version" yaml:"version"`
+	Result   ReviewResult `json:"result" yaml:"result"`
+	Datetime time.Time    `json:"datetime" yaml:"datetime"`
 }
 
 type ReviewSlice []Review
 
-func NewReview(result ReviewResult) Review {
+func NewReview(result ReviewResult, datetime time.Time) Review {
 	return Review{
 		Version:  0,
 		Result:   result,
-		Datetime: time.Now(),
+		Datetime: datetime,
 	}
 }
 
@@ -0,0 +1,330 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+)
+
+// The operators that may be used to compare a number, longest first
+// so that ">=" is not mistaken for ">".
+var operators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// Provides what some terms need to decide whether a card matches.
+type Context struct {
+	// Used by is:due. If nil, is:due is an error.
+	Scheduler scheduler.Scheduler
+	// Used by is:leech. See config.LeechConfig.
+	LeechThreshold uint
+}
+
+type term interface {
+	match(card *models.Card, context Context) (bool, error)
+}
+
+// A Query selects cards. It is made up of terms separated by
+// whitespace, and a card matches the query if it matches every term.
+// A term that starts with "-" matches cards that do not match the rest
+// of the term. The terms are:
+//
+//   - deck:NAME matches cards in the deck NAME. NAME may contain the
+//     wildcards "*" and "?".
+//   - id:ID matches the card with ID.
+//   - tag:TAG matches cards with the tag TAG.
+//   - is:due, is:new, is:active, is:inactive and is:leech match cards
+//     that are due, have not been reviewed, are active, are inactive,
+//     and are leeches, respectively.
+//   - question:TEXT and answer:TEXT match cards whose question or
+//     answer contains TEXT, ignoring case.
+//   - reviews and lapses followed by one of >, >=, <, <=, = or != and
+//     a number compare the number of reviews or failed reviews of
+//     cards to that number, for example reviews>5.
+//   - any other text matches cards whose question or answer contains
+//     it, ignoring case.
+//
+// Text that contains whitespace can be enclosed in double quotes,
+// for example "question text" or deck:"my deck".
+type Query struct {
+	terms []term
+}
+
+// Parses a query. See Query for the syntax.
+func Parse(s string) (*Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	query := &Query{}
+	for _, token := range tokens {
+		term, err := parseTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		query.terms = append(query.terms, term)
+	}
+	return query, nil
+}
+
+// Tells the caller whether card matches query.
+func (query *Query) Match(card *models.Card, context Context) (bool, error) {
+	for _, term := range query.terms {
+		matches, err := term.match(card, context)
+		if err != nil {
+			return false, err
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Returns the cards in cards that match query, in the same order.
+func (query *Query) Filter(cards []*models.Card, context Context) ([]*models.Card, error) {
+	matchingCards := make([]*models.Card, 0, len(cards))
+	for _, card := range cards {
+		matches, err := query.Match(card, context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to match card %q: %w", card.ID, err)
+		}
+		if matches {
+			matchingCards = append(matchingCards, card)
+		}
+	}
+	return matchingCards, nil
+}
+
+// A single whitespace-separated part of a query. negated is true if
+// the token started with "-", and quoted is true if the token (after
+// any "-") started with a double quote, which means it is text.
+type token struct {
+	text    string
+	negated bool
+	quoted  bool
+}
+
+// Splits s into tokens on whitespace that is not between double
+// quotes, and removes the quotes.
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	current := token{}
+	var builder strings.Builder
+	inToken := false
+	inQuotes := false
+	endToken := func() {
+		current.text = builder.String()
+		if current.negated && current.text == "" && !current.quoted {
+			// a lone "-" is text
+			current = token{text: "-"}
+		}
+		tokens = append(tokens, current)
+		current = token{}
+		builder.Reset()
+		inToken = false
+	}
+	for _, r := range s {
+		switch {
+		case r == '"':
+			if builder.Len() == 0 {
+				current.quoted = true
+			}
+			inToken = true
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if inToken {
+				endToken()
+			}
+		case r == '-' && !inToken:
+			inToken = true
+			current.negated = true
+		default:
+			inToken = true
+			builder.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("query has an unclosed double quote")
+	}
+	if inToken {
+		endToken()
+	}
+	return tokens, nil
+}
+
+func parseTerm(token token) (term, error) {
+	if token.negated {
+		token.negated = false
+		inner, err := parseTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		return notTerm{inner}, nil
+	}
+	text := token.text
+	if token.quoted {
+		return textTerm{text: strings.ToLower(text)}, nil
+	}
+
+	if key, value, found := strings.Cut(text, ":"); found {
+		switch key {
+		case "deck":
+			if _, err := path.Match(value, ""); err != nil {
+				return nil, fmt.Errorf("invalid deck pattern %q: %w", value, err)
+			}
+			return deckTerm{pattern: value}, nil
+		case "id":
+			return idTerm{id: value}, nil
+		case "tag":
+			return tagTerm{tag: value}, nil
+		case "is":
+			if _, ok := stateFuncs[value]; !ok {
+				return nil, fmt.Errorf("unknown state %q in %q", value, text)
+			}
+			return stateTerm{state: value}, nil
+		case "question":
+			return textTerm{text: strings.ToLower(value), inQuestion: true}, nil
+		case "answer":
+			return textTerm{text: strings.ToLower(value), inAnswer: true}, nil
+		default:
+			return nil, fmt.Errorf("unknown key %q in %q", key, text)
+		}
+	}
+
+	for _, field := range []string{"reviews", "lapses"} {
+		rest, found := strings.CutPrefix(text, field)
+		if !found {
+			continue
+		}
+		for _, operator := range operators {
+			valueString, found := strings.CutPrefix(rest, operator)
+			if !found {
+				continue
+			}
+			value, err := strconv.Atoi(valueString)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q in %q", valueString, text)
+			}
+			return comparisonTerm{field: field, operator: operator, value: value}, nil
+		}
+	}
+
+	return textTerm{text: strings.ToLower(text)}, nil
+}
+
+type notTerm struct {
+	inner term
+}
+
+func (t notTerm) match(card *models.Card, context Context) (bool, error) {
+	matches, err := t.inner.match(card, context)
+	return !matches, err
+}
+
+type deckTerm struct {
+	pattern string
+}
+
+func (t deckTerm) match(card *models.Card, context Context) (bool, error) {
+	return path.Match(t.pattern, card.Deck)
+}
+
+type idTerm struct {
+	id string
+}
+
+func (t idTerm) match(card *models.Card, context Context) (bool, error) {
+	return card.ID == t.id, nil
+}
+
+type tagTerm struct {
+	tag string
+}
+
+func (t tagTerm) match(card *models.Card, context Context) (bool, error) {
+	return card.HasTag(t.tag), nil
+}
+
+var stateFuncs = map[string]func(card *models.Card, context Context) (bool, error){
+	"due": func(card *models.Card, context Context) (bool, error) {
+		if context.Scheduler == nil {
+			return false, errors.New("is:due cannot be used here")
+		}
+		return context.Scheduler.IsDue(card)
+	},
+	"new": func(card *models.Card, context Context) (bool, error) {
+		return len(card.Reviews) == 0, nil
+	},
+	"active": func(card *models.Card, context Context) (bool, error) {
+		return card.Active, nil
+	},
+	"inactive": func(card *models.Card, context Context) (bool, error) {
+		return !card.Active, nil
+	},
+	"leech": func(card *models.Card, context Context) (bool, error) {
+		return card.HasTag(models.LeechTag) || card.IsLeech(context.LeechThreshold), nil
+	},
+}
+
+type stateTerm struct {
+	state string
+}
+
+func (t stateTerm) match(card *models.Card, context Context) (bool, error) {
+	return stateFuncs[t.state](card, context)
+}
+
+// Matches cards whose question or answer contains text. If either
+// inQuestion or inAnswer is true, only that part of the card is
+// searched. text must be lower case.
+type textTerm struct {
+	text       string
+	inQuestion bool
+	inAnswer   bool
+}
+
+func (t textTerm) match(card *models.Card, context Context) (bool, error) {
+	searchBoth := !t.inQuestion && !t.inAnswer
+	if (searchBoth || t.inQuestion) && strings.Contains(strings.ToLower(card.Question), t.text) {
+		return true, nil
+	}
+	if (searchBoth || t.inAnswer) && strings.Contains(strings.ToLower(card.Answer), t.text) {
+		return true, nil
+	}
+	return false, nil
+}
+
+type comparisonTerm struct {
+	field    string
+	operator string
+	value    int
+}
+
+func (t comparisonTerm) match(card *models.Card, context Context) (bool, error) {
+	var actual int
+	switch t.field {
+	case "reviews":
+		actual = len(card.Reviews)
+	case "lapses":
+		actual = card.LapseCount()
+	}
+	switch t.operator {
+	case ">":
+		return actual > t.value, nil
+	case ">=":
+		return actual >= t.value, nil
+	case "<":
+		return actual < t.value, nil
+	case "<=":
+		return actual <= t.value, nil
+	case "!=":
+		return actual != t.value, nil
+	default:
+		return actual == t.value, nil
+	}
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler under which only cards that have never been reviewed
+// are due.
+type newCardsDueScheduler struct{}
+
+func (newCardsDueScheduler) IsDue(card *models.Card) (bool, error) {
+	return len(card.Reviews) == 0, nil
+}
+
+func (newCardsDueScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func TestQuery(t *testing.T) {
+	french := models.NewCard("What is the French for cat?", "le chat", "french")
+	french.ID = "french1"
+	for _, result := range []models.ReviewResult{models.Failed, models.Failed, models.Normal} {
+		french.Reviews = append(french.Reviews, models.NewReview(result, time.Now()))
+	}
+	frenchNew := models.NewCard("What is the French for dog?", "le chien", "french")
+	frenchNew.ID = "french2"
+	german := models.NewCard("What is the German for cat?", "die Katze", "german")
+	german.ID = "german1"
+	german.Active = false
+	german.AddTag("grammar")
+	cards := []*models.Card{french, frenchNew, german}
+	context := Context{Scheduler: newCardsDueScheduler{}, LeechThreshold: 2}
+
+	testCases := []struct {
+		Query    string
+		Expected []string
+	}{
+		{"", []string{"french1", "french2", "german1"}},
+		{"deck:french", []string{"french1", "french2"}},
+		{"deck:fr*", []string{"french1", "french2"}},
+		{"-deck:french", []string{"german1"}},
+		{"id:german1", []string{"german1"}},
+		{"tag:grammar", []string{"german1"}},
+		{"is:due", []string{"french2", "german1"}},
+		{"is:due -is:inactive", []string{"french2"}},
+		{"is:new", []string{"french2", "german1"}},
+		{"is:leech", []string{"french1"}},
+		{"reviews>2", []string{"french1"}},
+		{"lapses>=3", []string{}},
+		{"lapses=0", []string{"french2", "german1"}},
+		{"cat", []string{"french1", "german1"}},
+		{`"french for cat"`, []string{"french1"}},
+		{`-"french for"`, []string{"german1"}},
+		{"answer:CH", []string{"french1", "french2"}},
+		{"question:chat", []string{}},
+		{`deck:french "for dog"`, []string{"french2"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Query, func(t *testing.T) {
+			query, err := Parse(testCase.Query)
+			if err != nil {
+				t.Fatalf("failed to parse query: %s", err)
+			}
+			matches, err := query.Filter(cards, context)
+			if err != nil {
+				t.Fatalf("failed to filter cards: %s", err)
+			}
+			ids := make([]string, 0, len(matches))
+			for _, card := range matches {
+				ids = append(ids, card.ID)
+			}
+			if len(ids) != len(testCase.Expected) {
+				t.Fatalf("got matches %v but expected %v", ids, testCase.Expected)
+			}
+			for i := range ids {
+				if ids[i] != testCase.Expected[i] {
+					t.Fatalf("got matches %v but expected %v", ids, testCase.Expected)
+				}
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, invalidQuery := range []string{`"unclosed`, "is:overdue", "color:red", "reviews>many", "deck:[", "-lapses<x"} {
+			if _, err := Parse(invalidQuery); err == nil {
+				t.Errorf("expected error for query %q", invalidQuery)
+			}
+		}
+	})
+}
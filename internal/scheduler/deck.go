@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler that schedules the cards of each deck with a scheduler
+// built from that deck's config. This allows decks to use different
+// schedulers, or the same scheduler with different settings. Cards
+// from decks that do not have their own config are scheduled using
+// the default config.
+type DeckScheduler struct {
+	defaultScheduler Scheduler
+	deckSchedulers   map[string]Scheduler
+}
+
+// Returns a DeckScheduler. deckConfigs maps deck names to the config
+// for each deck. clock is used to get the current time.
+func NewDeckScheduler(defaultConfig *config.Config, deckConfigs map[string]*config.Config, clock clock.Clock) (*DeckScheduler, error) {
+	defaultScheduler, err := New(defaultConfig, clock)
+	if err != nil {
+		return nil, err
+	}
+	deckSchedulers := make(map[string]Scheduler, len(deckConfigs))
+	for deckName, deckConfig := range deckConfigs {
+		deckScheduler, err := New(deckConfig, clock)
+		if err != nil {
+			return nil, fmt.Errorf("deck %q: %w", deckName, err)
+		}
+		deckSchedulers[deckName] = deckScheduler
+	}
+	return &DeckScheduler{
+		defaultScheduler: defaultScheduler,
+		deckSchedulers:   deckSchedulers,
+	}, nil
+}
+
+func (scheduler *DeckScheduler) IsDue(card *models.Card) (bool, error) {
+	return scheduler.getSchedulerFor(card).IsDue(card)
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *DeckScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	return scheduler.getSchedulerFor(card).GetNextReview(card)
+}
+
+func (scheduler *DeckScheduler) getSchedulerFor(card *models.Card) Scheduler {
+	if deckScheduler, ok := scheduler.deckSchedulers[card.Deck]; ok {
+		return deckScheduler
+	}
+	return scheduler.defaultScheduler
+}
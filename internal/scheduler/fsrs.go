@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// The number of weights used by the FSRS v4.5 model.
+const FSRSWeightCount = 17
+
+const (
+	fsrsDecay         = -0.5
+	fsrsFactor        = 19.0 / 81.0
+	fsrsMinDifficulty = 1.0
+	fsrsMaxDifficulty = 10.0
+	fsrsMinStability  = 0.01
+	hoursPerDay       = 24.0
+)
+
+// A scheduler that implements version 4.5 of the Free Spaced Repetition
+// Scheduler (FSRS). It replays the full review history of a card to
+// model the card's stability (the number of days after which the
+// probability of recalling it drops to 90%) and difficulty, and
+// schedules the next review for when the probability of recalling
+// the card drops to config.FSRS.RequestRetention.
+type FSRSScheduler struct {
+	config *config.Config
+	clock  clock.Clock
+}
+
+func NewFSRSScheduler(config *config.Config, clock clock.Clock) *FSRSScheduler {
+	return &FSRSScheduler{
+		config: config,
+		clock:  clock,
+	}
+}
+
+// The memory state of a card as modelled by FSRS.
+type fsrsState struct {
+	Stability  float64
+	Difficulty float64
+}
+
+func (scheduler *FSRSScheduler) IsDue(card *models.Card) (bool, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return true, nil
+	}
+
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review: %w", err)
+	}
+
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *FSRSScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return scheduler.clock.Now(), nil
+	}
+
+	if reviews[0].Result == models.Failed {
+		interval := time.Duration(scheduler.config.FailedReviewInterval) * time.Hour
+		return reviews[0].Datetime.Add(interval), nil
+	}
+
+	state, err := replayFSRS(scheduler.config.FSRS.Weights, reviews)
+	if err != nil {
+		return time.Time{}, err
+	}
+	intervalInDays := getFSRSInterval(state.Stability, scheduler.config.FSRS)
+	interval := time.Duration(intervalInDays * hoursPerDay * float64(time.Hour))
+	return reviews[0].Datetime.Add(interval), nil
+}
+
+// Replays reviews, which must be sorted from newest to oldest, and
+// returns the memory state of the card after the newest review.
+func replayFSRS(weights []float64, reviews models.ReviewSlice) (fsrsState, error) {
+	if len(weights) != FSRSWeightCount {
+		return fsrsState{}, fmt.Errorf("expected %d FSRS weights but got %d", FSRSWeightCount, len(weights))
+	}
+	var state fsrsState
+	for i := len(reviews) - 1; i >= 0; i-- {
+		grade, err := getFSRSGradeFor(reviews[i].Result)
+		if err != nil {
+			return fsrsState{}, err
+		}
+		if i == len(reviews)-1 {
+			state = getFSRSInitialState(weights, grade)
+			continue
+		}
+		elapsedDays := reviews[i].Datetime.Sub(reviews[i+1].Datetime).Hours() / hoursPerDay
+		state = getFSRSNextState(weights, state, grade, elapsedDays)
+	}
+	return state, nil
+}
+
+// Returns the memory state of a card after its first review.
+func getFSRSInitialState(weights []float64, grade float64) fsrsState {
+	return fsrsState{
+		Stability:  math.Max(weights[int(grade)-1], fsrsMinStability),
+		Difficulty: clampFSRSDifficulty(getFSRSInitialDifficulty(weights, grade)),
+	}
+}
+
+// Returns the memory state of a card after a review with the given
+// grade that took place elapsedDays after the previous review.
+func getFSRSNextState(weights []float64, state fsrsState, grade, elapsedDays float64) fsrsState {
+	retrievability := getFSRSRetrievability(math.Max(elapsedDays, 0), state.Stability)
+	var stability float64
+	if grade == 1 {
+		stability = weights[11] *
+			math.Pow(state.Difficulty, -weights[12]) *
+			(math.Pow(state.Stability+1, weights[13]) - 1) *
+			math.Exp(weights[14]*(1-retrievability))
+	} else {
+		hardPenalty := 1.0
+		if grade == 2 {
+			hardPenalty = weights[15]
+		}
+		easyBonus := 1.0
+		if grade == 4 {
+			easyBonus = weights[16]
+		}
+		stability = state.Stability * (1 + math.Exp(weights[8])*
+			(11-state.Difficulty)*
+			math.Pow(state.Stability, -weights[9])*
+			(math.Exp(weights[10]*(1-retrievability))-1)*
+			hardPenalty*
+			easyBonus)
+	}
+
+	difficulty := state.Difficulty - weights[6]*(grade-3)
+	difficulty = weights[7]*getFSRSInitialDifficulty(weights, 3) + (1-weights[7])*difficulty
+
+	return fsrsState{
+		Stability:  math.Max(stability, fsrsMinStability),
+		Difficulty: clampFSRSDifficulty(difficulty),
+	}
+}
+
+func getFSRSInitialDifficulty(weights []float64, grade float64) float64 {
+	return weights[4] - (grade-3)*weights[5]
+}
+
+func clampFSRSDifficulty(difficulty float64) float64 {
+	return math.Min(math.Max(difficulty, fsrsMinDifficulty), fsrsMaxDifficulty)
+}
+
+// Returns the probability of recalling a card elapsedDays after
+// it was last reviewed.
+func getFSRSRetrievability(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+// Returns the number of days until the probability of recalling a card
+// with the given stability drops to the requested retention. The result
+// is a whole number of days between 1 and the maximum interval.
+func getFSRSInterval(stability float64, fsrsConfig config.FSRSConfig) float64 {
+	interval := stability / fsrsFactor * (math.Pow(fsrsConfig.RequestRetention, 1/fsrsDecay) - 1)
+	interval = math.Round(interval)
+	return math.Min(math.Max(interval, 1), float64(fsrsConfig.MaximumInterval))
+}
+
+// Maps a ReviewResult to the 1-4 grade used by FSRS.
+func getFSRSGradeFor(result models.ReviewResult) (float64, error) {
+	switch result {
+	case models.Failed:
+		return 1, nil
+	case models.Hard:
+		return 2, nil
+	case models.Normal:
+		return 3, nil
+	case models.Easy:
+		return 4, nil
+	default:
+		return 0, fmt.Errorf("got unexpected result %q", result)
+	}
+}
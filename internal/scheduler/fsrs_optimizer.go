@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// The lower and upper bounds that the optimizer keeps each FSRS weight
+// within. These are the bounds used by the reference FSRS optimizer.
+var fsrsWeightBounds = [FSRSWeightCount][2]float64{
+	{0.1, 100}, {0.1, 100}, {0.1, 100}, {0.1, 100},
+	{1, 10}, {0.1, 5}, {0.1, 5}, {0, 0.75},
+	{0, 4}, {0, 0.8}, {0.01, 3}, {0.5, 5},
+	{0.01, 0.2}, {0.01, 0.9}, {0.01, 2}, {0, 1},
+	{1, 6},
+}
+
+var ErrNoReviewHistory error = errors.New("no cards have been reviewed more than once")
+
+// A single review in the history of a card, in the form used
+// for training FSRS weights.
+type fsrsTrainingReview struct {
+	Grade       float64
+	ElapsedDays float64
+}
+
+// Returns the mean log loss of the recall probabilities predicted
+// by FSRS with the given weights against the actual outcomes (passed
+// or failed) of every review except the first review of each card.
+func GetFSRSLogLoss(weights []float64, cards []*models.Card) (float64, error) {
+	histories, err := getFSRSTrainingHistories(cards)
+	if err != nil {
+		return 0, err
+	}
+	if len(weights) != FSRSWeightCount {
+		return 0, fmt.Errorf("expected %d FSRS weights but got %d", FSRSWeightCount, len(weights))
+	}
+	return getFSRSLogLoss(weights, histories), nil
+}
+
+// Fits FSRS weights to the review history of cards by minimizing
+// log loss using gradient descent, starting from initialWeights.
+// Returns the fitted weights.
+func OptimizeFSRSWeights(initialWeights []float64, cards []*models.Card, iterations int) ([]float64, error) {
+	if len(initialWeights) != FSRSWeightCount {
+		return nil, fmt.Errorf("expected %d FSRS weights but got %d", FSRSWeightCount, len(initialWeights))
+	}
+	histories, err := getFSRSTrainingHistories(cards)
+	if err != nil {
+		return nil, err
+	}
+
+	// This is the Adam optimizer, with the gradient approximated using
+	// central differences. Step sizes are scaled by the width of the
+	// bounds of each weight so that all weights move at similar rates.
+	const (
+		learningRate = 0.01
+		beta1        = 0.9
+		beta2        = 0.999
+		epsilon      = 1e-8
+		delta        = 1e-4
+	)
+	weights := make([]float64, FSRSWeightCount)
+	copy(weights, initialWeights)
+	clampFSRSWeights(weights)
+	bestWeights := make([]float64, FSRSWeightCount)
+	copy(bestWeights, weights)
+	bestLoss := getFSRSLogLoss(weights, histories)
+	firstMoment := make([]float64, FSRSWeightCount)
+	secondMoment := make([]float64, FSRSWeightCount)
+	gradient := make([]float64, FSRSWeightCount)
+	for iteration := 1; iteration <= iterations; iteration++ {
+		for i := range weights {
+			original := weights[i]
+			width := fsrsWeightBounds[i][1] - fsrsWeightBounds[i][0]
+			weights[i] = original + delta*width
+			upperLoss := getFSRSLogLoss(weights, histories)
+			weights[i] = original - delta*width
+			lowerLoss := getFSRSLogLoss(weights, histories)
+			weights[i] = original
+			gradient[i] = (upperLoss - lowerLoss) / (2 * delta)
+		}
+		for i := range weights {
+			firstMoment[i] = beta1*firstMoment[i] + (1-beta1)*gradient[i]
+			secondMoment[i] = beta2*secondMoment[i] + (1-beta2)*gradient[i]*gradient[i]
+			correctedFirstMoment := firstMoment[i] / (1 - math.Pow(beta1, float64(iteration)))
+			correctedSecondMoment := secondMoment[i] / (1 - math.Pow(beta2, float64(iteration)))
+			width := fsrsWeightBounds[i][1] - fsrsWeightBounds[i][0]
+			weights[i] -= learningRate * width * correctedFirstMoment / (math.Sqrt(correctedSecondMoment) + epsilon)
+		}
+		clampFSRSWeights(weights)
+		if loss := getFSRSLogLoss(weights, histories); loss < bestLoss {
+			bestLoss = loss
+			copy(bestWeights, weights)
+		}
+	}
+	return bestWeights, nil
+}
+
+func clampFSRSWeights(weights []float64) {
+	for i := range weights {
+		weights[i] = math.Min(math.Max(weights[i], fsrsWeightBounds[i][0]), fsrsWeightBounds[i][1])
+	}
+}
+
+func getFSRSLogLoss(weights []float64, histories [][]fsrsTrainingReview) float64 {
+	const minProbability = 1e-6
+	totalLoss := 0.0
+	count := 0
+	for _, history := range histories {
+		state := getFSRSInitialState(weights, history[0].Grade)
+		for _, review := range history[1:] {
+			retrievability := getFSRSRetrievability(review.ElapsedDays, state.Stability)
+			retrievability = math.Min(math.Max(retrievability, minProbability), 1-minProbability)
+			if review.Grade == 1 {
+				totalLoss -= math.Log(1 - retrievability)
+			} else {
+				totalLoss -= math.Log(retrievability)
+			}
+			count += 1
+			state = getFSRSNextState(weights, state, review.Grade, review.ElapsedDays)
+		}
+	}
+	return totalLoss / float64(count)
+}
+
+// Converts the reviews of each card that has been reviewed more than
+// once into a slice of fsrsTrainingReviews ordered from oldest to newest.
+func getFSRSTrainingHistories(cards []*models.Card) ([][]fsrsTrainingReview, error) {
+	histories := make([][]fsrsTrainingReview, 0, len(cards))
+	for _, card := range cards {
+		reviews := card.SortedReviews()
+		if len(reviews) < 2 {
+			continue
+		}
+		history := make([]fsrsTrainingReview, 0, len(reviews))
+		for i := len(reviews) - 1; i >= 0; i-- {
+			grade, err := getFSRSGradeFor(reviews[i].Result)
+			if err != nil {
+				return nil, fmt.Errorf("card %q: %w", card.ID, err)
+			}
+			elapsedDays := 0.0
+			if i < len(reviews)-1 {
+				elapsedDays = math.Max(reviews[i].Datetime.Sub(reviews[i+1].Datetime).Hours()/hoursPerDay, 0)
+			}
+			history = append(history, fsrsTrainingReview{
+				Grade:       grade,
+				ElapsedDays: elapsedDays,
+			})
+		}
+		histories = append(histories, history)
+	}
+	if len(histories) == 0 {
+		return nil, ErrNoReviewHistory
+	}
+	return histories, nil
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestOptimizeFSRSWeights(t *testing.T) {
+	t.Run("ReducesLoss", func(t *testing.T) {
+		// generate review histories where cards are forgotten much
+		// more often than the default weights predict
+		random := rand.New(rand.NewSource(1))
+		start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+		cards := []*models.Card{}
+		for i := 0; i < 50; i++ {
+			card := models.NewCard("question", "answer", "test_deck")
+			datetime := start
+			for j := 0; j < 6; j++ {
+				result := models.Normal
+				if random.Float64() < 0.5 {
+					result = models.Failed
+				}
+				review := models.Review{Result: result, Datetime: datetime}
+				card.Reviews = append(models.ReviewSlice{review}, card.Reviews...)
+				datetime = datetime.Add(time.Duration(j+1) * 72 * time.Hour)
+			}
+			cards = append(cards, card)
+		}
+
+		initialLoss, err := GetFSRSLogLoss(config.DefaultFSRSWeights, cards)
+		if err != nil {
+			t.Fatalf("failed to get initial loss: %s", err)
+		}
+		weights, err := OptimizeFSRSWeights(config.DefaultFSRSWeights, cards, 50)
+		if err != nil {
+			t.Fatalf("failed to optimize weights: %s", err)
+		}
+		finalLoss, err := GetFSRSLogLoss(weights, cards)
+		if err != nil {
+			t.Fatalf("failed to get final loss: %s", err)
+		}
+		if finalLoss >= initialLoss {
+			t.Errorf("final loss %f is not less than initial loss %f", finalLoss, initialLoss)
+		}
+	})
+
+	t.Run("NoHistory", func(t *testing.T) {
+		cards := []*models.Card{models.NewCard("question", "answer", "test_deck")}
+		_, err := OptimizeFSRSWeights(config.DefaultFSRSWeights, cards, 10)
+		if !errors.Is(err, ErrNoReviewHistory) {
+			t.Errorf("expected ErrNoReviewHistory but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestFSRSScheduler(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	scheduler := NewFSRSScheduler(config.DefaultConfig, clock.RealClock{})
+
+	t.Run("GetNextReview", func(t *testing.T) {
+		// with the default request retention of 0.9, the interval
+		// after a first review is the initial stability for its grade
+		card := newCardWithResults(lastReview, models.Easy)
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expected := 14 * 24 * time.Hour
+		if interval := nextReview.Sub(lastReview); interval != expected {
+			t.Errorf("got interval %s but expected %s", interval, expected)
+		}
+	})
+
+	t.Run("IntervalsGrow", func(t *testing.T) {
+		previousInterval := time.Duration(0)
+		results := []models.ReviewResult{}
+		for i := 0; i < 5; i++ {
+			results = append(results, models.Normal)
+			card := newCardWithResults(lastReview, results...)
+			nextReview, err := scheduler.GetNextReview(card)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			interval := nextReview.Sub(lastReview)
+			if interval < previousInterval {
+				t.Errorf("interval %s after %d reviews is less than previous interval %s", interval, i+1, previousInterval)
+			}
+			previousInterval = interval
+		}
+	})
+
+	t.Run("Lapse", func(t *testing.T) {
+		weights := config.DefaultFSRSWeights
+		card := newCardWithResults(lastReview, models.Normal, models.Normal, models.Failed)
+		state, err := replayFSRS(weights, card.SortedReviews())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		passedCard := newCardWithResults(lastReview, models.Normal, models.Normal)
+		passedState, err := replayFSRS(weights, passedCard.SortedReviews())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if state.Stability >= passedState.Stability {
+			t.Errorf("stability %f after lapse is not less than stability %f before it", state.Stability, passedState.Stability)
+		}
+		if state.Difficulty <= passedState.Difficulty {
+			t.Errorf("difficulty %f after lapse is not greater than difficulty %f before it", state.Difficulty, passedState.Difficulty)
+		}
+	})
+
+	t.Run("Retrievability", func(t *testing.T) {
+		stability := 10.0
+		if retrievability := getFSRSRetrievability(stability, stability); math.Abs(retrievability-0.9) > 1e-9 {
+			t.Errorf("got retrievability %f after stability days but expected 0.9", retrievability)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// Intervals shorter than this are not fuzzed.
+const minimumFuzzedInterval = 24 * time.Hour
+
+// A scheduler that randomly lengthens or shortens the intervals
+// computed by another scheduler by up to a fraction of their length.
+// This spreads out cards that would otherwise stay in lockstep
+// because they were reviewed together. The random amount is derived
+// from the ID and review count of the card, so a card's next review
+// does not change from one call to the next.
+type FuzzScheduler struct {
+	scheduler Scheduler
+	config    *config.Config
+	clock     clock.Clock
+}
+
+// Returns a FuzzScheduler that wraps scheduler. Intervals are fuzzed
+// by up to config.FuzzFactor.
+func NewFuzzScheduler(scheduler Scheduler, config *config.Config, clock clock.Clock) *FuzzScheduler {
+	return &FuzzScheduler{
+		scheduler: scheduler,
+		config:    config,
+		clock:     clock,
+	}
+}
+
+func (scheduler *FuzzScheduler) IsDue(card *models.Card) (bool, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return true, nil
+	}
+
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review: %w", err)
+	}
+
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *FuzzScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	nextReview, err := scheduler.scheduler.GetNextReview(card)
+	if err != nil {
+		return time.Time{}, err
+	}
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return nextReview, nil
+	}
+
+	interval := nextReview.Sub(reviews[0].Datetime)
+	if interval < minimumFuzzedInterval {
+		return nextReview, nil
+	}
+	random := rand.New(rand.NewSource(getFuzzSeed(card.ID, len(reviews))))
+	fuzz := (random.Float64()*2 - 1) * scheduler.config.FuzzFactor
+	fuzzedInterval := time.Duration(float64(interval) * (1 + fuzz))
+	return reviews[0].Datetime.Add(fuzzedInterval), nil
+}
+
+func getFuzzSeed(cardID string, reviewCount int) int64 {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s:%d", cardID, reviewCount)
+	return int64(hash.Sum64())
+}
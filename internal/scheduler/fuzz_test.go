@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestFuzzScheduler(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	baseScheduler := NewTwoReviewScheduler(config.DefaultConfig, clock.RealClock{})
+	factor := 0.1
+	fuzzConfig := config.DefaultConfig.Copy()
+	fuzzConfig.FuzzFactor = factor
+	scheduler := NewFuzzScheduler(baseScheduler, fuzzConfig, clock.RealClock{})
+
+	t.Run("Bounds", func(t *testing.T) {
+		distinctIntervals := map[time.Duration]struct{}{}
+		for i := 0; i < 100; i++ {
+			card := newCardWithResults(lastReview, models.Normal, models.Normal, models.Normal)
+			baseNextReview, err := baseScheduler.GetNextReview(card)
+			if err != nil {
+				t.Fatalf("failed to get base next review: %s", err)
+			}
+			nextReview, err := scheduler.GetNextReview(card)
+			if err != nil {
+				t.Fatalf("failed to get next review: %s", err)
+			}
+			baseInterval := baseNextReview.Sub(lastReview)
+			interval := nextReview.Sub(lastReview)
+			if float64(interval) < float64(baseInterval)*(1-factor) || float64(interval) > float64(baseInterval)*(1+factor) {
+				t.Errorf("fuzzed interval %s is outside of bounds for interval %s", interval, baseInterval)
+			}
+			distinctIntervals[interval] = struct{}{}
+		}
+		if len(distinctIntervals) < 50 {
+			t.Errorf("got only %d distinct intervals for 100 cards", len(distinctIntervals))
+		}
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		card := newCardWithResults(lastReview, models.Normal, models.Easy)
+		first, err := scheduler.GetNextReview(card)
+		if err != nil {
+			t.Fatalf("failed to get next review: %s", err)
+		}
+		second, err := scheduler.GetNextReview(card.Copy())
+		if err != nil {
+			t.Fatalf("failed to get next review: %s", err)
+		}
+		if !first.Equal(second) {
+			t.Errorf("got different next reviews %s and %s for the same card", first, second)
+		}
+	})
+
+	t.Run("ShortIntervals", func(t *testing.T) {
+		card := newCardWithResults(lastReview, models.Failed)
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			t.Fatalf("failed to get next review: %s", err)
+		}
+		expected := lastReview.Add(time.Duration(config.DefaultConfig.FailedReviewInterval) * time.Hour)
+		if !nextReview.Equal(expected) {
+			t.Errorf("got next review %s but expected unfuzzed %s", nextReview, expected)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler that takes new cards through config.LearningSteps, and
+// cards whose review was failed through config.RelearningSteps, before
+// handing them to another scheduler. Reviews made during learning steps,
+// apart from the review that completes the last step, are hidden from
+// the other scheduler so that short steps do not skew its intervals.
+type LearningScheduler struct {
+	scheduler Scheduler
+	config    *config.Config
+	clock     clock.Clock
+}
+
+func NewLearningScheduler(scheduler Scheduler, config *config.Config, clock clock.Clock) *LearningScheduler {
+	return &LearningScheduler{
+		scheduler: scheduler,
+		config:    config,
+		clock:     clock,
+	}
+}
+
+// Where a card is in the learning process.
+type learningState struct {
+	// The steps that the card is going through, or nil if the card
+	// has graduated.
+	Steps []time.Duration
+	// The index of the step that the card is on.
+	Step int
+	// The reviews of the card that the wrapped scheduler should see,
+	// sorted from newest to oldest.
+	GraduatedReviews models.ReviewSlice
+}
+
+func (scheduler *LearningScheduler) IsDue(card *models.Card) (bool, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return true, nil
+	}
+
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review: %w", err)
+	}
+
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *LearningScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return scheduler.clock.Now(), nil
+	}
+
+	state, err := scheduler.getLearningState(reviews)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if state.Steps != nil {
+		return reviews[0].Datetime.Add(state.Steps[state.Step]), nil
+	}
+	graduatedCard := card.Copy()
+	graduatedCard.Reviews = state.GraduatedReviews
+	return scheduler.scheduler.GetNextReview(graduatedCard)
+}
+
+// Replays reviews, which must be sorted from newest to oldest, and
+// returns the resulting learningState.
+func (scheduler *LearningScheduler) getLearningState(reviews models.ReviewSlice) (learningState, error) {
+	state := learningState{GraduatedReviews: models.ReviewSlice{}}
+	if len(scheduler.config.LearningSteps) > 0 {
+		state.Steps = scheduler.config.LearningSteps
+	}
+	for i := len(reviews) - 1; i >= 0; i-- {
+		review := reviews[i]
+
+		// handle graduated cards
+		if state.Steps == nil {
+			state.GraduatedReviews = append(models.ReviewSlice{review}, state.GraduatedReviews...)
+			if review.Result == models.Failed && len(scheduler.config.RelearningSteps) > 0 {
+				state.Steps = scheduler.config.RelearningSteps
+				state.Step = 0
+			}
+			continue
+		}
+
+		// handle cards that are going through steps
+		switch review.Result {
+		case models.Failed:
+			state.Step = 0
+		case models.Hard:
+		case models.Normal:
+			state.Step += 1
+		case models.Easy:
+			state.Step = len(state.Steps)
+		default:
+			return learningState{}, fmt.Errorf("got unexpected result %q", review.Result)
+		}
+		if state.Step >= len(state.Steps) {
+			state.Steps = nil
+			state.Step = 0
+			state.GraduatedReviews = append(models.ReviewSlice{review}, state.GraduatedReviews...)
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestLearningScheduler(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	testConfig := config.DefaultConfig.Copy()
+	testConfig.LearningSteps = []time.Duration{time.Minute, 10 * time.Minute}
+	testConfig.RelearningSteps = []time.Duration{10 * time.Minute}
+	testClock := clock.NewFixedClock(lastReview)
+	baseScheduler := NewSM2Scheduler(testConfig, testClock)
+	scheduler := NewLearningScheduler(baseScheduler, testConfig, testClock)
+
+	testCases := []struct {
+		Name     string
+		Results  []models.ReviewResult
+		Interval time.Duration
+	}{
+		{"FirstStep", []models.ReviewResult{models.Failed}, time.Minute},
+		{"RepeatStep", []models.ReviewResult{models.Normal, models.Hard}, 10 * time.Minute},
+		{"SecondStep", []models.ReviewResult{models.Normal}, 10 * time.Minute},
+		{"Graduate", []models.ReviewResult{models.Normal, models.Normal}, 24 * time.Hour},
+		{"GraduateEarly", []models.ReviewResult{models.Easy}, 24 * time.Hour},
+		{"FailBackToFirstStep", []models.ReviewResult{models.Normal, models.Failed}, time.Minute},
+		{"Relearn", []models.ReviewResult{models.Easy, models.Normal, models.Failed}, 10 * time.Minute},
+		{"GraduateFromRelearning", []models.ReviewResult{models.Easy, models.Normal, models.Failed, models.Normal}, 24 * time.Hour},
+	}
+	for _, testCase := range testCases {
+		card := newCardWithResults(lastReview, testCase.Results...)
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", testCase.Name, err)
+		}
+		if interval := nextReview.Sub(lastReview); interval != testCase.Interval {
+			t.Errorf("%s: got interval %s but expected %s", testCase.Name, interval, testCase.Interval)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler that implements the Leitner box system. Every card
+// starts out in the first box. Passing a review moves a card up one
+// box, and failing a review moves it back to the first box. How long
+// until a card is due depends only on the box it is in.
+type LeitnerScheduler struct {
+	config *config.Config
+	clock  clock.Clock
+}
+
+func NewLeitnerScheduler(config *config.Config, clock clock.Clock) *LeitnerScheduler {
+	return &LeitnerScheduler{
+		config: config,
+		clock:  clock,
+	}
+}
+
+func (scheduler *LeitnerScheduler) IsDue(card *models.Card) (bool, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return true, nil
+	}
+
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review: %w", err)
+	}
+
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *LeitnerScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return scheduler.clock.Now(), nil
+	}
+
+	box, err := scheduler.GetBox(card)
+	if err != nil {
+		return time.Time{}, err
+	}
+	intervalInHours := scheduler.config.Leitner.BoxIntervals[box]
+	interval := time.Duration(intervalInHours) * time.Hour
+	return reviews[0].Datetime.Add(interval), nil
+}
+
+// Returns the zero-based index of the box that card is currently in.
+func (scheduler *LeitnerScheduler) GetBox(card *models.Card) (int, error) {
+	boxCount := len(scheduler.config.Leitner.BoxIntervals)
+	if boxCount == 0 {
+		return 0, errors.New("no Leitner boxes are configured")
+	}
+
+	reviews := card.SortedReviews()
+	box := 0
+	for i := len(reviews) - 1; i >= 0; i-- {
+		switch reviews[i].Result {
+		case models.Failed:
+			box = 0
+		case models.Hard, models.Normal, models.Easy:
+			box = min(box+1, boxCount-1)
+		default:
+			return 0, fmt.Errorf("got unexpected result %q", reviews[i].Result)
+		}
+	}
+	return box, nil
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestLeitnerScheduler(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	testConfig := *config.DefaultConfig
+	testConfig.Leitner.BoxIntervals = []uint{24, 48, 96}
+	scheduler := NewLeitnerScheduler(&testConfig, clock.RealClock{})
+
+	testCases := []struct {
+		Name     string
+		Results  []models.ReviewResult
+		Box      int
+		Interval time.Duration
+	}{
+		{"NoReviews", []models.ReviewResult{}, 0, 0},
+		{"OnePass", []models.ReviewResult{models.Normal}, 1, 48 * time.Hour},
+		{"PassesStopAtLastBox", []models.ReviewResult{models.Hard, models.Normal, models.Easy, models.Normal}, 2, 96 * time.Hour},
+		{"FailureResetsBox", []models.ReviewResult{models.Normal, models.Normal, models.Failed}, 0, 24 * time.Hour},
+		{"PassAfterFailure", []models.ReviewResult{models.Normal, models.Failed, models.Normal}, 1, 48 * time.Hour},
+	}
+	for _, testCase := range testCases {
+		card := newCardWithResults(lastReview, testCase.Results...)
+		box, err := scheduler.GetBox(card)
+		if err != nil {
+			t.Fatalf("%s: failed to get box: %s", testCase.Name, err)
+		}
+		if box != testCase.Box {
+			t.Errorf("%s: got box %d but expected %d", testCase.Name, box, testCase.Box)
+		}
+		if len(testCase.Results) == 0 {
+			continue
+		}
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			t.Fatalf("%s: failed to get next review: %s", testCase.Name, err)
+		}
+		if interval := nextReview.Sub(lastReview); interval != testCase.Interval {
+			t.Errorf("%s: got interval %s but expected %s", testCase.Name, interval, testCase.Interval)
+		}
+	}
+}
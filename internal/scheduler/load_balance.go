@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/utils"
+)
+
+// A scheduler that moves the next review computed by another scheduler
+// to the day with the fewest reviews due, within a window around the
+// day of that next review. The number of reviews due on each day is
+// forecast when the LoadBalancer is created, and the forecast is kept
+// up to date as cards are moved, so that cards that were due on the
+// same day are spread out rather than all being moved to the same day.
+type LoadBalancer struct {
+	scheduler Scheduler
+	config    *config.Config
+	clock     clock.Clock
+	// Maps the start of each day, as a Unix timestamp, to the number
+	// of cards that are due on that day.
+	forecast map[int64]int
+	// Maps the ID of each card in the forecast to the day it
+	// was counted on.
+	cardDays map[string]int64
+	// Maps the ID of each card that has been balanced to its next
+	// review before and after balancing.
+	balancedReviews map[string]balancedReview
+}
+
+type balancedReview struct {
+	original time.Time
+	balanced time.Time
+}
+
+// Returns a LoadBalancer that wraps scheduler. The next reviews of
+// the active cards among cards are used to forecast the number of
+// reviews due on each day. The cards are then balanced one at a time,
+// in order of their next review, so that the result does not depend
+// on the order in which cards are passed to GetNextReview. The window
+// is set by config.LoadBalance. clock is used to get the current time.
+func NewLoadBalancer(scheduler Scheduler, cards []*models.Card, config *config.Config, clock clock.Clock) (*LoadBalancer, error) {
+	loadBalancer := &LoadBalancer{
+		scheduler:       scheduler,
+		config:          config,
+		clock:           clock,
+		forecast:        map[int64]int{},
+		cardDays:        map[string]int64{},
+		balancedReviews: map[string]balancedReview{},
+	}
+	activeCards := []*models.Card{}
+	nextReviews := map[string]time.Time{}
+	for _, card := range cards {
+		if !card.Active {
+			continue
+		}
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+		}
+		day := utils.StartOfDay(nextReview, config.DayStartHour).Unix()
+		loadBalancer.forecast[day] += 1
+		loadBalancer.cardDays[card.ID] = day
+		activeCards = append(activeCards, card)
+		nextReviews[card.ID] = nextReview
+	}
+
+	slices.SortFunc(activeCards, func(a, b *models.Card) int {
+		if result := nextReviews[a.ID].Compare(nextReviews[b.ID]); result != 0 {
+			return result
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, card := range activeCards {
+		loadBalancer.balance(card, nextReviews[card.ID])
+	}
+	return loadBalancer, nil
+}
+
+func (scheduler *LoadBalancer) IsDue(card *models.Card) (bool, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return true, nil
+	}
+
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review: %w", err)
+	}
+
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *LoadBalancer) GetNextReview(card *models.Card) (time.Time, error) {
+	nextReview, err := scheduler.scheduler.GetNextReview(card)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if balanced, ok := scheduler.balancedReviews[card.ID]; ok && balanced.original.Equal(nextReview) {
+		return balanced.balanced, nil
+	}
+	if !card.Active {
+		return scheduler.getBalancedReview(card, nextReview), nil
+	}
+	return scheduler.balance(card, nextReview), nil
+}
+
+// Moves nextReview, the next review of card before balancing, to the
+// quietest day in its window, and updates the forecast to match.
+// Returns the balanced next review.
+func (scheduler *LoadBalancer) balance(card *models.Card, nextReview time.Time) time.Time {
+	balancedNextReview := scheduler.getBalancedReview(card, nextReview)
+	if oldDay, ok := scheduler.cardDays[card.ID]; ok {
+		scheduler.forecast[oldDay] -= 1
+	}
+	newDay := utils.StartOfDay(balancedNextReview, scheduler.config.DayStartHour).Unix()
+	scheduler.forecast[newDay] += 1
+	scheduler.cardDays[card.ID] = newDay
+	scheduler.balancedReviews[card.ID] = balancedReview{
+		original: nextReview,
+		balanced: balancedNextReview,
+	}
+	return balancedNextReview
+}
+
+// Returns the day in the window around nextReview with the fewest
+// reviews due, at the same time of day as nextReview.
+func (scheduler *LoadBalancer) getBalancedReview(card *models.Card, nextReview time.Time) time.Time {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return nextReview
+	}
+
+	// find the size of the window
+	intervalInDays := nextReview.Sub(reviews[0].Datetime).Hours() / hoursPerDay
+	window := int(math.Floor(intervalInDays * scheduler.config.LoadBalance.WindowFactor))
+	window = min(window, int(scheduler.config.LoadBalance.MaximumWindow))
+	if window < 1 {
+		return nextReview
+	}
+
+	// Find the day in the window with the fewest reviews due. Days
+	// before today and days on or before the last review are not
+	// considered. Ties go to the day closest to the original day.
+	today := utils.StartOfDay(scheduler.clock.Now(), scheduler.config.DayStartHour)
+	lastReviewDay := utils.StartOfDay(reviews[0].Datetime, scheduler.config.DayStartHour)
+	if utils.StartOfDay(nextReview, scheduler.config.DayStartHour).Before(today) {
+		return nextReview
+	}
+	bestOffset := 0
+	bestLoad := scheduler.getLoad(card, nextReview)
+	for distance := 1; distance <= window; distance++ {
+		for _, offset := range []int{-distance, distance} {
+			candidate := nextReview.AddDate(0, 0, offset)
+			candidateDay := utils.StartOfDay(candidate, scheduler.config.DayStartHour)
+			if candidateDay.Before(today) || !candidateDay.After(lastReviewDay) {
+				continue
+			}
+			if load := scheduler.getLoad(card, candidate); load < bestLoad {
+				bestOffset = offset
+				bestLoad = load
+			}
+		}
+	}
+	return nextReview.AddDate(0, 0, bestOffset)
+}
+
+// Returns the number of reviews other than the review of card
+// that are forecast for the day of t.
+func (scheduler *LoadBalancer) getLoad(card *models.Card, t time.Time) int {
+	day := utils.StartOfDay(t, scheduler.config.DayStartHour).Unix()
+	load := scheduler.forecast[day]
+	if cardDay, ok := scheduler.cardDays[card.ID]; ok && cardDay == day {
+		load -= 1
+	}
+	return load
+}
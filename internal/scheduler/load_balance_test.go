@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler that returns a fixed next review for each card.
+type fixedScheduler map[string]time.Time
+
+func (scheduler fixedScheduler) IsDue(card *models.Card) (bool, error) {
+	return false, nil
+}
+
+func (scheduler fixedScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	return scheduler[card.ID], nil
+}
+
+func TestLoadBalancer(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	testClock := clock.NewFixedClock(lastReview)
+	day := 24 * time.Hour
+	loadBalanceConfig := config.DefaultConfig.Copy()
+	loadBalanceConfig.LoadBalance = config.LoadBalanceConfig{WindowFactor: 0.1, MaximumWindow: 7}
+
+	// 3 cards due in 10 days, 2 in 9 days and 1 in 11 days
+	baseScheduler := fixedScheduler{}
+	cards := []*models.Card{}
+	for i, daysUntilDue := range []int{10, 10, 10, 9, 9, 11} {
+		card := newCardWithResults(lastReview, models.Normal)
+		card.ID = string(rune('a' + i))
+		baseScheduler[card.ID] = lastReview.Add(time.Duration(daysUntilDue) * day)
+		cards = append(cards, card)
+	}
+
+	t.Run("MovesToQuietestDay", func(t *testing.T) {
+		scheduler, err := NewLoadBalancer(baseScheduler, cards, loadBalanceConfig, testClock)
+		if err != nil {
+			t.Fatalf("failed to create load balancer: %s", err)
+		}
+		nextReview, err := scheduler.GetNextReview(cards[0])
+		if err != nil {
+			t.Fatalf("failed to get next review: %s", err)
+		}
+		expected := lastReview.AddDate(0, 0, 11)
+		if !nextReview.Equal(expected) {
+			t.Errorf("got next review %s but expected %s", nextReview, expected)
+		}
+	})
+
+	t.Run("SpreadsClump", func(t *testing.T) {
+		// the result should not depend on the order cards are asked about
+		for _, order := range [][]int{{0, 1, 2}, {2, 1, 0}} {
+			scheduler, err := NewLoadBalancer(baseScheduler, cards, loadBalanceConfig, testClock)
+			if err != nil {
+				t.Fatalf("failed to create load balancer: %s", err)
+			}
+			daysUntilDue := map[string]int{}
+			for _, i := range order {
+				nextReview, err := scheduler.GetNextReview(cards[i])
+				if err != nil {
+					t.Fatalf("failed to get next review: %s", err)
+				}
+				daysUntilDue[cards[i].ID] = int(nextReview.Sub(lastReview) / day)
+			}
+			expected := map[string]int{"a": 11, "b": 10, "c": 10}
+			for id, expectedDays := range expected {
+				if daysUntilDue[id] != expectedDays {
+					t.Errorf("with order %v, card %s is due in %d days but expected %d", order, id, daysUntilDue[id], expectedDays)
+				}
+			}
+		}
+	})
+
+	t.Run("StaysWithinWindow", func(t *testing.T) {
+		narrowConfig := loadBalanceConfig.Copy()
+		narrowConfig.LoadBalance.MaximumWindow = 0
+		scheduler, err := NewLoadBalancer(baseScheduler, cards, narrowConfig, testClock)
+		if err != nil {
+			t.Fatalf("failed to create load balancer: %s", err)
+		}
+		nextReview, err := scheduler.GetNextReview(cards[0])
+		if err != nil {
+			t.Fatalf("failed to get next review: %s", err)
+		}
+		if !nextReview.Equal(baseScheduler[cards[0].ID]) {
+			t.Errorf("got next review %s but expected it to be unchanged", nextReview)
+		}
+	})
+}
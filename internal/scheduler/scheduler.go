@@ -1,12 +1,77 @@
 package scheduler
 
 import (
-	"github.com/adamkpickering/clsr/internal/models"
+	"fmt"
 	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/utils"
+)
+
+const (
+	TwoReviewSchedulerName = "two-review"
+	SM2SchedulerName       = "sm2"
+	FSRSSchedulerName      = "fsrs"
+	LeitnerSchedulerName   = "leitner"
 )
 
+// The names of all of the schedulers that can be selected.
+var SchedulerNames = []string{
+	TwoReviewSchedulerName,
+	SM2SchedulerName,
+	FSRSSchedulerName,
+	LeitnerSchedulerName,
+}
+
 // A scheduler tells you whether cards are due, and when they will be due.
 type Scheduler interface {
 	IsDue(card *models.Card) (bool, error)
 	GetNextReview(card *models.Card) (time.Time, error)
-}
\ No newline at end of file
+}
+
+// Returns the Scheduler named by config.Scheduler. If config.Scheduler
+// is empty, the TwoReviewScheduler is returned. If learning steps are
+// configured, the Scheduler is wrapped in a LearningScheduler. If
+// config.FuzzFactor is set, the Scheduler is wrapped in a FuzzScheduler.
+// clock is used to get the current time.
+func New(config *config.Config, clock clock.Clock) (Scheduler, error) {
+	var scheduler Scheduler
+	switch config.Scheduler {
+	case "", TwoReviewSchedulerName:
+		scheduler = NewTwoReviewScheduler(config, clock)
+	case SM2SchedulerName:
+		scheduler = NewSM2Scheduler(config, clock)
+	case FSRSSchedulerName:
+		scheduler = NewFSRSScheduler(config, clock)
+	case LeitnerSchedulerName:
+		scheduler = NewLeitnerScheduler(config, clock)
+	default:
+		return nil, fmt.Errorf("unknown scheduler %q", config.Scheduler)
+	}
+
+	if len(config.LearningSteps) > 0 || len(config.RelearningSteps) > 0 {
+		scheduler = NewLearningScheduler(scheduler, config, clock)
+	}
+	if config.FuzzFactor > 0 {
+		scheduler = NewFuzzScheduler(scheduler, config, clock)
+	}
+	return scheduler, nil
+}
+
+// Tells the caller whether a card is due, given its reviews sorted
+// from newest to oldest and the time of its next review. If the next
+// review falls on the same day as the last review, the card is due
+// once the exact time of the next review has passed. Otherwise, the
+// card is due from the start of the day of the next review. Days
+// start at dayStartHour. now is the current time.
+func isDue(reviews models.ReviewSlice, nextReview time.Time, dayStartHour uint, now time.Time) bool {
+	if len(reviews) == 0 {
+		return true
+	}
+	if utils.DatesEqual(reviews[0].Datetime, nextReview, dayStartHour) {
+		return now.After(nextReview)
+	}
+	return now.After(utils.StartOfDay(nextReview, dayStartHour))
+}
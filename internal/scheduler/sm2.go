@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler that implements the SuperMemo SM-2 algorithm. It replays
+// the full review history of a card to find the card's ease factor
+// and current interval. A failed review resets the card's run of
+// successful reviews and lowers its ease factor.
+type SM2Scheduler struct {
+	config *config.Config
+	clock  clock.Clock
+}
+
+func NewSM2Scheduler(config *config.Config, clock clock.Clock) *SM2Scheduler {
+	return &SM2Scheduler{
+		config: config,
+		clock:  clock,
+	}
+}
+
+func (scheduler *SM2Scheduler) IsDue(card *models.Card) (bool, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return true, nil
+	}
+
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review: %w", err)
+	}
+
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
+}
+
+// Returns the datetime that the card is next due.
+func (scheduler *SM2Scheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	reviews := card.SortedReviews()
+	if len(reviews) == 0 {
+		return scheduler.clock.Now(), nil
+	}
+
+	_, interval, err := scheduler.replay(reviews)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return reviews[0].Datetime.Add(interval), nil
+}
+
+// Replays reviews, which must be sorted from newest to oldest, and
+// returns the resulting ease factor and the interval until the next review.
+func (scheduler *SM2Scheduler) replay(reviews models.ReviewSlice) (float64, time.Duration, error) {
+	sm2Config := scheduler.config.SM2
+	easeFactor := sm2Config.InitialEaseFactor
+	var interval time.Duration
+	repetitions := 0
+	for i := len(reviews) - 1; i >= 0; i-- {
+		quality, err := getSM2QualityFor(reviews[i].Result)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		easeFactor += 0.1 - (5-quality)*(0.08+(5-quality)*0.02)
+		easeFactor = math.Max(easeFactor, sm2Config.MinimumEaseFactor)
+
+		if quality < 3 {
+			repetitions = 0
+			interval = time.Duration(scheduler.config.FailedReviewInterval) * time.Hour
+			continue
+		}
+		repetitions += 1
+		switch repetitions {
+		case 1:
+			interval = time.Duration(sm2Config.FirstInterval) * time.Hour
+		case 2:
+			interval = time.Duration(sm2Config.SecondInterval) * time.Hour
+		default:
+			interval = time.Duration(float64(interval) * easeFactor)
+		}
+	}
+	return easeFactor, interval, nil
+}
+
+// Maps a ReviewResult to the 0-5 response quality used by SM-2.
+func getSM2QualityFor(result models.ReviewResult) (float64, error) {
+	switch result {
+	case models.Failed:
+		return 2, nil
+	case models.Hard:
+		return 3, nil
+	case models.Normal:
+		return 4, nil
+	case models.Easy:
+		return 5, nil
+	default:
+		return 0, fmt.Errorf("got unexpected result %q", result)
+	}
+}
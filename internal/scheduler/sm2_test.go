@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// Returns a card with reviews that have the passed results. The
+// reviews are one day apart, with the last one at lastReview.
+func newCardWithResults(lastReview time.Time, results ...models.ReviewResult) *models.Card {
+	card := models.NewCard("question", "answer", "test_deck")
+	for i, result := range results {
+		daysAgo := len(results) - 1 - i
+		review := models.Review{
+			Result:   result,
+			Datetime: lastReview.Add(-time.Duration(daysAgo) * 24 * time.Hour),
+		}
+		card.Reviews = append(models.ReviewSlice{review}, card.Reviews...)
+	}
+	return card
+}
+
+func TestSM2Scheduler(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	scheduler := NewSM2Scheduler(config.DefaultConfig, clock.RealClock{})
+
+	t.Run("GetNextReview", func(t *testing.T) {
+		testCases := []struct {
+			Name     string
+			Results  []models.ReviewResult
+			Expected time.Duration
+		}{
+			{"FirstReview", []models.ReviewResult{models.Normal}, 24 * time.Hour},
+			{"SecondReview", []models.ReviewResult{models.Normal, models.Normal}, 144 * time.Hour},
+			{"ThirdReview", []models.ReviewResult{models.Normal, models.Normal, models.Normal}, 360 * time.Hour},
+			{"Lapse", []models.ReviewResult{models.Normal, models.Normal, models.Failed}, 4 * time.Hour},
+			{"AfterLapse", []models.ReviewResult{models.Normal, models.Normal, models.Failed, models.Normal}, 24 * time.Hour},
+		}
+		for _, testCase := range testCases {
+			card := newCardWithResults(lastReview, testCase.Results...)
+			nextReview, err := scheduler.GetNextReview(card)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", testCase.Name, err)
+			}
+			if interval := nextReview.Sub(lastReview); interval != testCase.Expected {
+				t.Errorf("%s: got interval %s but expected %s", testCase.Name, interval, testCase.Expected)
+			}
+		}
+	})
+
+	t.Run("EaseFactor", func(t *testing.T) {
+		card := newCardWithResults(lastReview, models.Hard, models.Failed, models.Failed, models.Failed, models.Failed)
+		easeFactor, _, err := scheduler.replay(card.SortedReviews())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if easeFactor != config.DefaultConfig.SM2.MinimumEaseFactor {
+			t.Errorf("got ease factor %f but expected minimum of %f", easeFactor, config.DefaultConfig.SM2.MinimumEaseFactor)
+		}
+	})
+}
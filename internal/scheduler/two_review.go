@@ -2,28 +2,29 @@ package scheduler
 
 import (
 	"fmt"
-	"sort"
 	"time"
 
+	"github.com/adamkpickering/clsr/internal/clock"
 	"github.com/adamkpickering/clsr/internal/config"
 	"github.com/adamkpickering/clsr/internal/models"
-	"github.com/adamkpickering/clsr/internal/utils"
 )
 
 // A scheduler that looks at the most recent two reviews
 // of a card to determine when it should be reviewed next.
 type TwoReviewScheduler struct {
 	config *config.Config
+	clock  clock.Clock
 }
 
-func NewTwoReviewScheduler(config *config.Config) *TwoReviewScheduler {
+func NewTwoReviewScheduler(config *config.Config, clock clock.Clock) *TwoReviewScheduler {
 	return &TwoReviewScheduler{
 		config: config,
+		clock:  clock,
 	}
 }
 
 func (scheduler *TwoReviewScheduler) IsDue(card *models.Card) (bool, error) {
-	reviews := getSortedReviewsCopy(card)
+	reviews := card.SortedReviews()
 	if len(reviews) == 0 {
 		return true, nil
 	}
@@ -33,21 +34,15 @@ func (scheduler *TwoReviewScheduler) IsDue(card *models.Card) (bool, error) {
 		return false, fmt.Errorf("failed to get next review: %w", err)
 	}
 
-	if utils.DatesEqual(reviews[0].Datetime, nextReview) {
-		return time.Now().After(nextReview), nil
-	} else {
-		nextReviewYear, nextReviewMonth, nextReviewDay := nextReview.Date()
-		midnightNextReview := time.Date(nextReviewYear, nextReviewMonth, nextReviewDay, 0, 0, 0, 0, nextReview.Location())
-		return time.Now().After(midnightNextReview), nil
-	}
+	return isDue(reviews, nextReview, scheduler.config.DayStartHour, scheduler.clock.Now()), nil
 }
 
 // Returns the datetime that the card is next due.
 func (scheduler *TwoReviewScheduler) GetNextReview(card *models.Card) (time.Time, error) {
-	reviews := getSortedReviewsCopy(card)
+	reviews := card.SortedReviews()
 	reviewsLength := len(reviews)
 	if reviewsLength == 0 {
-		return time.Now(), nil
+		return scheduler.clock.Now(), nil
 	}
 
 	if reviews[0].Result == models.Failed {
@@ -100,10 +95,3 @@ func getMultiplierFor(result models.ReviewResult, config *config.Config) (float6
 		return 0.0, fmt.Errorf("got unexpected result %q", result)
 	}
 }
-
-func getSortedReviewsCopy(card *models.Card) models.ReviewSlice {
-	reviews := make(models.ReviewSlice, len(card.Reviews))
-	copy(reviews, card.Reviews)
-	sort.Stable(reviews)
-	return reviews
-}
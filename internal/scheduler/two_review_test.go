@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestTwoReviewScheduler(t *testing.T) {
+	lastReview := time.Date(2023, 3, 1, 12, 0, 0, 0, time.Local)
+	testClock := clock.NewFixedClock(lastReview)
+	scheduler := NewTwoReviewScheduler(config.DefaultConfig, testClock)
+
+	t.Run("IsDue", func(t *testing.T) {
+		testCases := []struct {
+			Name    string
+			Results []models.ReviewResult
+			Now     time.Time
+			Due     bool
+		}{
+			{"NoReviews", []models.ReviewResult{}, lastReview, true},
+			{"FailedBeforeInterval", []models.ReviewResult{models.Failed}, lastReview.Add(3 * time.Hour), false},
+			{"FailedAfterInterval", []models.ReviewResult{models.Failed}, lastReview.Add(5 * time.Hour), true},
+			{"NormalSameDay", []models.ReviewResult{models.Normal}, lastReview.Add(11 * time.Hour), false},
+			{"NormalStartOfNextDay", []models.ReviewResult{models.Normal}, lastReview.Add(12*time.Hour + time.Minute), true},
+		}
+		for _, testCase := range testCases {
+			testClock.Set(testCase.Now)
+			card := newCardWithResults(lastReview, testCase.Results...)
+			due, err := scheduler.IsDue(card)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", testCase.Name, err)
+			}
+			if due != testCase.Due {
+				t.Errorf("%s: got due %t but expected %t", testCase.Name, due, testCase.Due)
+			}
+		}
+	})
+}
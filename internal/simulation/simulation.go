@@ -0,0 +1,124 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/adamkpickering/clsr/internal/utils"
+)
+
+// The pass rate that is used when there is no review history to
+// measure it from.
+const DefaultPassRate = 0.9
+
+type Options struct {
+	// The number of days to simulate, starting with the current day.
+	Days int
+	// The probability that a simulated review is not failed.
+	PassRate float64
+	// Seeds the random numbers that decide which reviews are failed,
+	// so that a simulation can be repeated.
+	Seed         int64
+	DayStartHour uint
+}
+
+// The simulated workload of a single day.
+type Day struct {
+	// The start of the day.
+	Date time.Time
+	// The number of reviews, including the reviews of new cards and
+	// failed reviews.
+	Reviews int
+	// The number of cards that were reviewed for the first time.
+	NewCards int
+	// The number of reviews that were failed.
+	Failed int
+}
+
+// Simulates studying cards every day for options.Days days, and
+// returns the workload of each day. cards are copied, so they are not
+// modified. scheduler must get the current time from clock, which is
+// moved forward as the simulation proceeds. Each day, the cards that
+// are due are reviewed at the time of day that clock is set to when
+// Run is called, and cards that come due again later on the same day
+// are reviewed as soon as they come due.
+func Run(cards []*models.Card, scheduler scheduler.Scheduler, clock *clock.FixedClock, options Options) ([]Day, error) {
+	simulatedCards := make([]*models.Card, 0, len(cards))
+	for _, card := range cards {
+		simulatedCards = append(simulatedCards, card.Copy())
+	}
+	random := rand.New(rand.NewSource(options.Seed))
+
+	start := clock.Now()
+	days := make([]Day, 0, options.Days)
+	for i := 0; i < options.Days; i++ {
+		clock.Set(start.AddDate(0, 0, i))
+		day := Day{
+			Date: utils.StartOfDay(clock.Now(), options.DayStartHour),
+		}
+		endOfDay := day.Date.AddDate(0, 0, 1)
+		for {
+			for _, card := range simulatedCards {
+				isDue, err := scheduler.IsDue(card)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check whether card %q is due: %w", card.ID, err)
+				}
+				if !isDue {
+					continue
+				}
+				result := models.Normal
+				if random.Float64() >= options.PassRate {
+					result = models.Failed
+					day.Failed += 1
+				}
+				if len(card.Reviews) == 0 {
+					day.NewCards += 1
+				}
+				day.Reviews += 1
+				review := models.NewReview(result, clock.Now())
+				card.Reviews = append(models.ReviewSlice{review}, card.Reviews...)
+			}
+
+			// move on to the next time that a card comes due today
+			nextDue := endOfDay
+			for _, card := range simulatedCards {
+				nextReview, err := scheduler.GetNextReview(card)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+				}
+				if nextReview.After(clock.Now()) && nextReview.Before(nextDue) {
+					nextDue = nextReview
+				}
+			}
+			if !nextDue.Before(endOfDay) {
+				break
+			}
+			clock.Set(nextDue.Add(time.Second))
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Returns the fraction of the reviews of cards that were not failed.
+// The second return value is false if cards have no reviews.
+func MeasurePassRate(cards []*models.Card) (float64, bool) {
+	total := 0
+	passed := 0
+	for _, card := range cards {
+		for _, review := range card.Reviews {
+			total += 1
+			if review.Result != models.Failed {
+				passed += 1
+			}
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(passed) / float64(total), true
+}
@@ -0,0 +1,75 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+)
+
+func TestRun(t *testing.T) {
+	start := time.Date(2024, 5, 14, 8, 0, 0, 0, time.UTC)
+
+	t.Run("AllPassed", func(t *testing.T) {
+		clock := clock.NewFixedClock(start)
+		sm2 := scheduler.NewSM2Scheduler(config.DefaultConfig, clock)
+		card := models.NewCard("question", "answer", "test_deck")
+		days, err := Run([]*models.Card{card}, sm2, clock, Options{Days: 8, PassRate: 1})
+		if err != nil {
+			t.Fatalf("failed to run simulation: %s", err)
+		}
+		if len(days) != 8 {
+			t.Fatalf("got %d days but expected 8", len(days))
+		}
+		// SM-2 reviews a card again after 1 day and then after 6 days
+		expectedReviews := []int{1, 1, 0, 0, 0, 0, 0, 1}
+		for i, day := range days {
+			if day.Reviews != expectedReviews[i] {
+				t.Errorf("got %d reviews on day %d but expected %d", day.Reviews, i, expectedReviews[i])
+			}
+			if day.Failed != 0 {
+				t.Errorf("got %d failed reviews on day %d but expected 0", day.Failed, i)
+			}
+		}
+		if days[0].NewCards != 1 {
+			t.Errorf("got %d new cards on day 0 but expected 1", days[0].NewCards)
+		}
+		if len(card.Reviews) != 0 {
+			t.Errorf("simulation modified the passed card")
+		}
+	})
+
+	t.Run("AllFailed", func(t *testing.T) {
+		clock := clock.NewFixedClock(start)
+		sm2 := scheduler.NewSM2Scheduler(config.DefaultConfig, clock)
+		card := models.NewCard("question", "answer", "test_deck")
+		days, err := Run([]*models.Card{card}, sm2, clock, Options{Days: 2, PassRate: 0})
+		if err != nil {
+			t.Fatalf("failed to run simulation: %s", err)
+		}
+		// a failed card comes due again 4 hours later: at 08:00,
+		// 12:00, 16:00 and 20:00
+		for i, day := range days {
+			if day.Reviews != 4 || day.Failed != 4 {
+				t.Errorf("got %d reviews and %d failed on day %d but expected 4 and 4", day.Reviews, day.Failed, i)
+			}
+		}
+	})
+}
+
+func TestMeasurePassRate(t *testing.T) {
+	if _, ok := MeasurePassRate([]*models.Card{}); ok {
+		t.Errorf("expected no pass rate for cards without reviews")
+	}
+	card := models.NewCard("question", "answer", "test_deck")
+	for _, result := range []models.ReviewResult{models.Failed, models.Normal, models.Easy, models.Hard} {
+		card.Reviews = append(card.Reviews, models.NewReview(result, time.Now()))
+	}
+	passRate, ok := MeasurePassRate([]*models.Card{card})
+	if !ok || passRate != 0.75 {
+		t.Errorf("got pass rate %g (%t) but expected 0.75", passRate, ok)
+	}
+}
@@ -0,0 +1,286 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/adamkpickering/clsr/internal/utils"
+)
+
+// A review is of a mature card if the time since the review before
+// it is at least this long.
+const MatureInterval = 21 * 24 * time.Hour
+
+// The results in the order in which they are usually displayed.
+var Results = []models.ReviewResult{models.Failed, models.Hard, models.Normal, models.Easy}
+
+type Stats struct {
+	CardCount   int
+	ReviewCount int
+	// The average number of reviews per day, from the day of the first
+	// review up to and including the current day.
+	ReviewsPerDay float64
+	// The number of reviews with each result.
+	ResultCounts map[models.ReviewResult]int
+	// The number of reviews of mature cards, and how many of them were
+	// not failed. The ratio of these is the true retention.
+	MatureReviewCount  int
+	MatureReviewPassed int
+	// The number of consecutive days with at least one review, up to
+	// and including the current day. If there are no reviews on the
+	// current day, the streak ends on the previous day instead, since
+	// the current day is not over yet.
+	CurrentStreak int
+	// The longest number of consecutive days with at least one review.
+	LongestStreak int
+	// The average time between the last review of each active card
+	// that has been reviewed and its next review.
+	AverageInterval time.Duration
+}
+
+// Returns the fraction of mature reviews that were not failed. The
+// second return value is false if there are no mature reviews.
+func (stats *Stats) Retention() (float64, bool) {
+	if stats.MatureReviewCount == 0 {
+		return 0, false
+	}
+	return float64(stats.MatureReviewPassed) / float64(stats.MatureReviewCount), true
+}
+
+// Computes Stats for cards. scheduler is used to get the next review
+// of each card, and now is the current time.
+func Compute(cards []*models.Card, scheduler scheduler.Scheduler, now time.Time, dayStartHour uint) (*Stats, error) {
+	stats := &Stats{
+		CardCount:    len(cards),
+		ResultCounts: map[models.ReviewResult]int{},
+	}
+
+	var totalInterval time.Duration
+	intervalCount := 0
+	for _, card := range cards {
+		reviews := card.SortedReviews()
+
+		stats.ReviewCount += len(reviews)
+		for i, review := range reviews {
+			stats.ResultCounts[review.Result] += 1
+			if i+1 < len(reviews) && review.Datetime.Sub(reviews[i+1].Datetime) >= MatureInterval {
+				stats.MatureReviewCount += 1
+				if review.Result != models.Failed {
+					stats.MatureReviewPassed += 1
+				}
+			}
+		}
+
+		if !card.Active || len(reviews) == 0 {
+			continue
+		}
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+		}
+		totalInterval += nextReview.Sub(reviews[0].Datetime)
+		intervalCount += 1
+	}
+	if intervalCount > 0 {
+		stats.AverageInterval = totalInterval / time.Duration(intervalCount)
+	}
+
+	dailyCounts := GetDailyReviewCounts(cards, dayStartHour)
+	days := GetReviewDays(dailyCounts, now.Location())
+	today := utils.StartOfDay(now, dayStartHour)
+	if len(days) > 0 {
+		dayCount := max(getDaysBetween(days[0], today)+1, 1)
+		stats.ReviewsPerDay = float64(stats.ReviewCount) / float64(dayCount)
+	}
+
+	stats.CurrentStreak, stats.LongestStreak = GetStreaks(dailyCounts, now, dayStartHour)
+
+	return stats, nil
+}
+
+// Returns the current and longest streaks of days with at least one
+// review in dailyCounts. See Stats.CurrentStreak and Stats.LongestStreak.
+func GetStreaks(dailyCounts map[int64]int, now time.Time, dayStartHour uint) (int, int) {
+	currentStreak := 0
+	streakDay := utils.StartOfDay(now, dayStartHour)
+	if dailyCounts[streakDay.Unix()] == 0 {
+		streakDay = streakDay.AddDate(0, 0, -1)
+	}
+	for dailyCounts[streakDay.Unix()] > 0 {
+		currentStreak += 1
+		streakDay = streakDay.AddDate(0, 0, -1)
+	}
+
+	longestStreak := 0
+	streak := 0
+	days := GetReviewDays(dailyCounts, now.Location())
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			streak += 1
+		} else {
+			streak = 1
+		}
+		longestStreak = max(longestStreak, streak)
+	}
+	return currentStreak, longestStreak
+}
+
+// Returns the number of reviews of cards on each day. The keys are
+// the start of each day as a Unix timestamp.
+func GetDailyReviewCounts(cards []*models.Card, dayStartHour uint) map[int64]int {
+	counts := map[int64]int{}
+	for _, card := range cards {
+		for _, review := range card.Reviews {
+			counts[utils.StartOfDay(review.Datetime, dayStartHour).Unix()] += 1
+		}
+	}
+	return counts
+}
+
+// Returns the days in dailyCounts that have at least one review, in
+// chronological order and in location.
+func GetReviewDays(dailyCounts map[int64]int, location *time.Location) []time.Time {
+	days := make([]time.Time, 0, len(dailyCounts))
+	for day, count := range dailyCounts {
+		if count > 0 {
+			days = append(days, time.Unix(day, 0).In(location))
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+	return days
+}
+
+// Returns the number of active cards in cards that come due on each
+// of the next days days, starting with the current day. Cards that
+// are due now are counted on the current day, and cards that come due
+// later are not counted.
+func GetDueForecast(cards []*models.Card, scheduler scheduler.Scheduler, now time.Time, dayStartHour uint, days int) ([]int, error) {
+	forecast := make([]int, days)
+	today := utils.StartOfDay(now, dayStartHour)
+	for _, card := range cards {
+		if !card.Active {
+			continue
+		}
+		dayIndex := 0
+		isDue, err := scheduler.IsDue(card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check whether card %q is due: %w", card.ID, err)
+		}
+		if !isDue {
+			nextReview, err := scheduler.GetNextReview(card)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+			}
+			dayIndex = max(getDaysBetween(today, utils.StartOfDay(nextReview, dayStartHour)), 0)
+		}
+		if dayIndex < days {
+			forecast[dayIndex] += 1
+		}
+	}
+	return forecast, nil
+}
+
+// The retention of the reviews in a single period of time.
+type PeriodRetention struct {
+	// The start of the first day of the period.
+	Start time.Time
+	// The number of reviews of cards that had been reviewed before,
+	// and how many of them were not failed.
+	ReviewCount  int
+	ReviewPassed int
+	// The same as ReviewCount and ReviewPassed, but only for reviews
+	// of mature cards.
+	MatureReviewCount  int
+	MatureReviewPassed int
+}
+
+// Returns the retention in each of the last weeks weeks, oldest first.
+// The last week is the current day and the 6 days before it.
+func GetWeeklyRetention(cards []*models.Card, now time.Time, dayStartHour uint, weeks int) []PeriodRetention {
+	today := utils.StartOfDay(now, dayStartHour)
+	firstDay := today.AddDate(0, 0, -7*weeks+1)
+	periods := make([]PeriodRetention, weeks)
+	for i := range periods {
+		periods[i].Start = firstDay.AddDate(0, 0, 7*i)
+	}
+	for _, card := range cards {
+		reviews := card.SortedReviews()
+		for i := 0; i+1 < len(reviews); i++ {
+			review := reviews[i]
+			dayIndex := getDaysBetween(firstDay, utils.StartOfDay(review.Datetime, dayStartHour))
+			if dayIndex < 0 || dayIndex >= 7*weeks {
+				continue
+			}
+			period := &periods[dayIndex/7]
+			passed := review.Result != models.Failed
+			period.ReviewCount += 1
+			if passed {
+				period.ReviewPassed += 1
+			}
+			if review.Datetime.Sub(reviews[i+1].Datetime) >= MatureInterval {
+				period.MatureReviewCount += 1
+				if passed {
+					period.MatureReviewPassed += 1
+				}
+			}
+		}
+	}
+	return periods
+}
+
+// The number of cards whose interval is less than Max, and at least
+// the Max of the bucket before it.
+type IntervalBucket struct {
+	Label string
+	Max   time.Duration
+	Count int
+}
+
+// Returns the number of active cards with intervals in each of a set
+// of ranges. The interval of a card is the time between its last
+// review and its next review. Cards that have not been reviewed are
+// not counted.
+func GetIntervalDistribution(cards []*models.Card, scheduler scheduler.Scheduler) ([]IntervalBucket, error) {
+	day := 24 * time.Hour
+	buckets := []IntervalBucket{
+		{Label: "<1d", Max: day},
+		{Label: "1-2d", Max: 3 * day},
+		{Label: "3-6d", Max: 7 * day},
+		{Label: "1-2w", Max: 14 * day},
+		{Label: "2-4w", Max: 30 * day},
+		{Label: "1-3mo", Max: 90 * day},
+		{Label: "3-6mo", Max: 180 * day},
+		{Label: "6-12mo", Max: 365 * day},
+		{Label: "1y+", Max: math.MaxInt64},
+	}
+	for _, card := range cards {
+		if !card.Active || len(card.Reviews) == 0 {
+			continue
+		}
+		reviews := card.SortedReviews()
+		nextReview, err := scheduler.GetNextReview(card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+		}
+		interval := nextReview.Sub(reviews[0].Datetime)
+		for i := range buckets {
+			if interval < buckets[i].Max {
+				buckets[i].Count += 1
+				break
+			}
+		}
+	}
+	return buckets, nil
+}
+
+// Returns the number of days from the start of one day to the start
+// of another, which may be negative.
+func getDaysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
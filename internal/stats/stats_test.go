@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+// A scheduler that schedules every card two days after its last
+// review. Cards that have not been reviewed are due.
+type twoDayScheduler struct{}
+
+func (twoDayScheduler) IsDue(card *models.Card) (bool, error) {
+	return len(card.Reviews) == 0, nil
+}
+
+func (twoDayScheduler) GetNextReview(card *models.Card) (time.Time, error) {
+	if len(card.Reviews) == 0 {
+		return time.Time{}, nil
+	}
+	return card.Reviews[0].Datetime.Add(48 * time.Hour), nil
+}
+
+// Returns a card with the passed reviews, which must be in
+// chronological order.
+func newCardWithReviews(reviews ...models.Review) *models.Card {
+	card := models.NewCard("question", "answer", "test_deck")
+	for _, review := range reviews {
+		card.Reviews = append(models.ReviewSlice{review}, card.Reviews...)
+	}
+	return card
+}
+
+func TestCompute(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 10, 0, 0, 0, time.UTC)
+	}
+	cards := []*models.Card{
+		newCardWithReviews(
+			models.NewReview(models.Normal, date(5, 12)),
+			models.NewReview(models.Normal, date(5, 13)),
+			models.NewReview(models.Normal, date(5, 14)),
+		),
+		newCardWithReviews(
+			models.NewReview(models.Normal, date(4, 1)),
+			models.NewReview(models.Failed, date(5, 10)),
+			models.NewReview(models.Easy, date(5, 13)),
+		),
+		models.NewCard("new question", "new answer", "test_deck"),
+	}
+	now := time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC)
+
+	stats, err := Compute(cards, twoDayScheduler{}, now, 0)
+	if err != nil {
+		t.Fatalf("failed to compute stats: %s", err)
+	}
+	if stats.CardCount != 3 || stats.ReviewCount != 6 {
+		t.Errorf("got %d cards and %d reviews but expected 3 and 6", stats.CardCount, stats.ReviewCount)
+	}
+	// there are 44 days from April 1 to May 14
+	if expected := 6.0 / 44; stats.ReviewsPerDay != expected {
+		t.Errorf("got %g reviews per day but expected %g", stats.ReviewsPerDay, expected)
+	}
+	expectedCounts := map[models.ReviewResult]int{models.Failed: 1, models.Hard: 0, models.Normal: 4, models.Easy: 1}
+	for result, expected := range expectedCounts {
+		if count := stats.ResultCounts[result]; count != expected {
+			t.Errorf("got %d %s reviews but expected %d", count, result, expected)
+		}
+	}
+	if retention, ok := stats.Retention(); !ok || retention != 0 {
+		t.Errorf("got retention %g (%t) but expected 0 from one failed mature review", retention, ok)
+	}
+	if stats.CurrentStreak != 3 || stats.LongestStreak != 3 {
+		t.Errorf("got current streak %d and longest streak %d but expected 3 and 3", stats.CurrentStreak, stats.LongestStreak)
+	}
+	if stats.AverageInterval != 48*time.Hour {
+		t.Errorf("got average interval %s but expected 48h", stats.AverageInterval)
+	}
+
+	t.Run("StreakNotOverUntilDayEnds", func(t *testing.T) {
+		stats, err := Compute(cards, twoDayScheduler{}, now.AddDate(0, 0, 1), 0)
+		if err != nil {
+			t.Fatalf("failed to compute stats: %s", err)
+		}
+		if stats.CurrentStreak != 3 {
+			t.Errorf("got current streak %d but expected 3", stats.CurrentStreak)
+		}
+	})
+
+	t.Run("StreakBroken", func(t *testing.T) {
+		stats, err := Compute(cards, twoDayScheduler{}, now.AddDate(0, 0, 2), 0)
+		if err != nil {
+			t.Fatalf("failed to compute stats: %s", err)
+		}
+		if stats.CurrentStreak != 0 || stats.LongestStreak != 3 {
+			t.Errorf("got current streak %d and longest streak %d but expected 0 and 3", stats.CurrentStreak, stats.LongestStreak)
+		}
+	})
+}
+
+func TestGetDueForecast(t *testing.T) {
+	now := time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC)
+	inactiveCard := newCardWithReviews(models.NewReview(models.Normal, now))
+	inactiveCard.Active = false
+	cards := []*models.Card{
+		models.NewCard("new question", "new answer", "test_deck"),
+		newCardWithReviews(models.NewReview(models.Normal, now)),
+		newCardWithReviews(models.NewReview(models.Normal, now.AddDate(0, 0, -1))),
+		newCardWithReviews(models.NewReview(models.Normal, now.AddDate(0, 0, 5))),
+		inactiveCard,
+	}
+	forecast, err := GetDueForecast(cards, twoDayScheduler{}, now, 0, 4)
+	if err != nil {
+		t.Fatalf("failed to get forecast: %s", err)
+	}
+	expected := []int{1, 1, 1, 0}
+	for i := range expected {
+		if forecast[i] != expected[i] {
+			t.Errorf("got forecast %v but expected %v", forecast, expected)
+			break
+		}
+	}
+}
+
+func TestGetWeeklyRetention(t *testing.T) {
+	now := time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC)
+	card := newCardWithReviews(
+		models.NewReview(models.Normal, now.AddDate(0, 0, -40)),
+		models.NewReview(models.Failed, now.AddDate(0, 0, -10)),
+		models.NewReview(models.Normal, now.AddDate(0, 0, -9)),
+		models.NewReview(models.Hard, now),
+	)
+	periods := GetWeeklyRetention([]*models.Card{card}, now, 0, 2)
+	if len(periods) != 2 {
+		t.Fatalf("got %d periods but expected 2", len(periods))
+	}
+	expectedStart := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if !periods[0].Start.Equal(expectedStart) {
+		t.Errorf("got start %s but expected %s", periods[0].Start, expectedStart)
+	}
+	if p := periods[0]; p.ReviewCount != 2 || p.ReviewPassed != 1 || p.MatureReviewCount != 1 || p.MatureReviewPassed != 0 {
+		t.Errorf("got %+v for first period", p)
+	}
+	if p := periods[1]; p.ReviewCount != 1 || p.ReviewPassed != 1 || p.MatureReviewCount != 0 {
+		t.Errorf("got %+v for second period", p)
+	}
+}
+
+func TestGetIntervalDistribution(t *testing.T) {
+	now := time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC)
+	cards := []*models.Card{
+		models.NewCard("new question", "new answer", "test_deck"),
+		newCardWithReviews(models.NewReview(models.Normal, now)),
+		newCardWithReviews(models.NewReview(models.Normal, now)),
+	}
+	buckets, err := GetIntervalDistribution(cards, twoDayScheduler{})
+	if err != nil {
+		t.Fatalf("failed to get interval distribution: %s", err)
+	}
+	for _, bucket := range buckets {
+		expected := 0
+		if bucket.Label == "1-2d" {
+			expected = 2
+		}
+		if bucket.Count != expected {
+			t.Errorf("got %d cards in bucket %s but expected %d", bucket.Count, bucket.Label, expected)
+		}
+	}
+}
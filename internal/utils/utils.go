@@ -2,30 +2,42 @@ package utils
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/adamkpickering/clsr/internal/deck_source"
 	"github.com/adamkpickering/clsr/internal/models"
 )
 
-// Tells the caller whether the two passed times have the same date.
-func DatesEqual(time1 time.Time, time2 time.Time) bool {
-	time1Year, time1Month, time1Day := time1.Date()
-	time2Year, time2Month, time2Day := time2.Date()
-	return time1Year == time2Year && time1Month == time2Month && time1Day == time2Day
+// Tells the caller whether the two passed times fall on the same day.
+// Days start at dayStartHour rather than at midnight.
+func DatesEqual(time1 time.Time, time2 time.Time, dayStartHour uint) bool {
+	return StartOfDay(time1, dayStartHour).Equal(StartOfDay(time2, dayStartHour))
 }
 
-// Returns the time in hours (if on the same day) or days (if on different days)
-// between two time.Times as a human-readable string. For example, "11h" or "23d".
+// Returns the start of the day that t falls on, where days start at
+// dayStartHour rather than at midnight.
+func StartOfDay(t time.Time, dayStartHour uint) time.Time {
+	year, month, day := t.Add(-time.Duration(dayStartHour) * time.Hour).Date()
+	return time.Date(year, month, day, int(dayStartHour), 0, 0, 0, t.Location())
+}
+
+// Returns the time in minutes (if less than an hour), hours (if on the same day)
+// or days (if on different days) between two time.Times as a human-readable
+// string. For example, "10m", "11h" or "23d".
 // time1 should be before time2, otherwise a negative duration will be returned.
-func GetReadableTimeDifference(time1, time2 time.Time) string {
-	if DatesEqual(time1, time2) {
+// Days start at dayStartHour rather than at midnight.
+func GetReadableTimeDifference(time1, time2 time.Time, dayStartHour uint) string {
+	if DatesEqual(time1, time2, dayStartHour) {
 		difference := time2.Sub(time1)
+		if difference < time.Hour {
+			minutes := difference / time.Minute
+			return fmt.Sprintf("%dm", minutes)
+		}
 		hours := difference / time.Hour
 		return fmt.Sprintf("%dh", hours)
 	} else {
-		midnightTime1 := time.Date(time1.Year(), time1.Month(), time1.Day(), 0, 0, 0, 0, time1.Location())
-		difference := time2.Sub(midnightTime1)
+		difference := time2.Sub(StartOfDay(time1, dayStartHour))
 		day := 24 * time.Hour
 		days := difference / day
 		return fmt.Sprintf("%dd", days)
@@ -59,6 +71,23 @@ func GetDecks(deckSource deck_source.DeckSource, passedDeckNames ...string) ([]*
 	return decks, nil
 }
 
+// Returns the decks in decks that are named in deckNames, in the order
+// of deckNames. If deckNames is empty, decks is returned.
+func SelectDecks(decks []*models.Deck, deckNames ...string) ([]*models.Deck, error) {
+	if len(deckNames) == 0 {
+		return decks, nil
+	}
+	selectedDecks := make([]*models.Deck, 0, len(deckNames))
+	for _, deckName := range deckNames {
+		index := slices.IndexFunc(decks, func(deck *models.Deck) bool { return deck.Name == deckName })
+		if index < 0 {
+			return []*models.Deck{}, fmt.Errorf("failed to find deck %q", deckName)
+		}
+		selectedDecks = append(selectedDecks, decks[index])
+	}
+	return selectedDecks, nil
+}
+
 func GetCards(deckSource deck_source.DeckSource, passedDeckNames ...string) ([]*models.Card, error) {
 	decks, err := GetDecks(deckSource, passedDeckNames...)
 	if err != nil {
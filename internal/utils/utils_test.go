@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/models"
+)
+
+func TestDayBoundary(t *testing.T) {
+	location := time.FixedZone("test", -7*60*60)
+	lateEvening := time.Date(2023, 3, 1, 23, 30, 0, 0, location)
+	justAfterMidnight := time.Date(2023, 3, 2, 0, 30, 0, 0, location)
+	morning := time.Date(2023, 3, 2, 9, 0, 0, 0, location)
+
+	t.Run("StartOfDay", func(t *testing.T) {
+		expected := time.Date(2023, 3, 1, 4, 0, 0, 0, location)
+		if start := StartOfDay(justAfterMidnight, 4); !start.Equal(expected) {
+			t.Errorf("got start of day %s but expected %s", start, expected)
+		}
+		expected = time.Date(2023, 3, 2, 0, 0, 0, 0, location)
+		if start := StartOfDay(justAfterMidnight, 0); !start.Equal(expected) {
+			t.Errorf("got start of day %s but expected %s", start, expected)
+		}
+	})
+
+	t.Run("DatesEqual", func(t *testing.T) {
+		if DatesEqual(lateEvening, justAfterMidnight, 0) {
+			t.Errorf("times on either side of midnight are on the same day when days start at midnight")
+		}
+		if !DatesEqual(lateEvening, justAfterMidnight, 4) {
+			t.Errorf("times on either side of midnight are not on the same day when days start at 4am")
+		}
+		if DatesEqual(justAfterMidnight, morning, 4) {
+			t.Errorf("times on either side of 4am are on the same day when days start at 4am")
+		}
+	})
+
+	t.Run("GetReadableTimeDifference", func(t *testing.T) {
+		if difference := GetReadableTimeDifference(lateEvening, justAfterMidnight, 4); difference != "1h" {
+			t.Errorf("got difference %q but expected 1h", difference)
+		}
+		if difference := GetReadableTimeDifference(justAfterMidnight, morning, 4); difference != "1d" {
+			t.Errorf("got difference %q but expected 1d", difference)
+		}
+		if difference := GetReadableTimeDifference(lateEvening, lateEvening.Add(10*time.Minute), 4); difference != "10m" {
+			t.Errorf("got difference %q but expected 10m", difference)
+		}
+	})
+}
+
+func TestSelectDecks(t *testing.T) {
+	decks := []*models.Deck{models.NewDeck("deck1", true), models.NewDeck("deck2", true)}
+
+	t.Run("All", func(t *testing.T) {
+		selectedDecks, err := SelectDecks(decks)
+		if err != nil {
+			t.Fatalf("failed to select decks: %s", err)
+		}
+		if len(selectedDecks) != 2 {
+			t.Errorf("got %d decks but expected 2", len(selectedDecks))
+		}
+	})
+
+	t.Run("Named", func(t *testing.T) {
+		selectedDecks, err := SelectDecks(decks, "deck2")
+		if err != nil {
+			t.Fatalf("failed to select decks: %s", err)
+		}
+		if len(selectedDecks) != 1 || selectedDecks[0] != decks[1] {
+			t.Errorf("expected only deck2 to be selected")
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		if _, err := SelectDecks(decks, "deck3"); err == nil {
+			t.Errorf("expected error for deck that does not exist")
+		}
+	})
+}
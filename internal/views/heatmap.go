@@ -0,0 +1,127 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/utils"
+)
+
+// The number of levels of activity that are shown in a heatmap,
+// including the level for days without reviews.
+const HeatmapLevels = 5
+
+// The 256-colour terminal colours of each level of activity.
+var heatmapColors = [HeatmapLevels]int{237, 22, 28, 34, 40}
+
+// The characters used for each level of activity when colour is off.
+var heatmapRunes = [HeatmapLevels]rune{'·', '░', '▒', '▓', '█'}
+
+// A calendar of the number of reviews on each day, in the style of the
+// contributions calendar on GitHub. There is a column for each week and
+// a row for each day of the week, starting with Sunday.
+type Heatmap struct {
+	// The first day of the heatmap. This is always a Sunday.
+	Start time.Time
+	// The number of reviews on each day, indexed by week and then by
+	// day of the week. Days after the last day of the heatmap are -1.
+	Counts [][]int
+	// The largest number of reviews on a single day.
+	Max int
+}
+
+// Returns a Heatmap of weeks weeks that ends on the day that contains
+// end. dailyCounts maps the start of each day, as a Unix timestamp,
+// to the number of reviews on that day; see stats.GetDailyReviewCounts.
+func NewHeatmap(dailyCounts map[int64]int, end time.Time, weeks int, dayStartHour uint) *Heatmap {
+	lastDay := utils.StartOfDay(end, dayStartHour)
+	start := lastDay.AddDate(0, 0, -int(lastDay.Weekday())-7*(weeks-1))
+	heatmap := &Heatmap{
+		Start:  start,
+		Counts: make([][]int, weeks),
+	}
+	day := start
+	for week := range heatmap.Counts {
+		heatmap.Counts[week] = make([]int, 7)
+		for weekday := range heatmap.Counts[week] {
+			if day.After(lastDay) {
+				heatmap.Counts[week][weekday] = -1
+			} else {
+				count := dailyCounts[day.Unix()]
+				heatmap.Counts[week][weekday] = count
+				heatmap.Max = max(heatmap.Max, count)
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	return heatmap
+}
+
+// Returns the level of activity, from 0 to HeatmapLevels-1, of a day
+// with count reviews. The levels are relative to the busiest day.
+func (heatmap *Heatmap) Level(count int) int {
+	if count <= 0 || heatmap.Max == 0 {
+		return 0
+	}
+	// round up so that any day with reviews is at least level 1
+	return (count*(HeatmapLevels-1) + heatmap.Max - 1) / heatmap.Max
+}
+
+// Returns the first day of the given week of the heatmap.
+func (heatmap *Heatmap) WeekStart(week int) time.Time {
+	return heatmap.Start.AddDate(0, 0, 7*week)
+}
+
+// Returns the heatmap drawn as lines of text, with a line of month
+// names above it and a legend below it. Each day is two characters
+// wide. If color is true, days are coloured using ANSI escape codes.
+func (heatmap *Heatmap) Render(color bool) string {
+	var builder strings.Builder
+	rowLabels := []string{"", "Mon", "", "Wed", "", "Fri", ""}
+	labelWidth := 4
+
+	// add month names above the weeks in which months start
+	monthLine := []rune(strings.Repeat(" ", labelWidth+2*len(heatmap.Counts)))
+	lastLabelEnd := 0
+	for week := range heatmap.Counts {
+		weekStart := heatmap.WeekStart(week)
+		if week > 0 && weekStart.Month() == heatmap.WeekStart(week-1).Month() {
+			continue
+		}
+		position := labelWidth + 2*week
+		label := weekStart.Format("Jan")
+		if position < lastLabelEnd || position+len(label) > len(monthLine) {
+			continue
+		}
+		copy(monthLine[position:], []rune(label))
+		lastLabelEnd = position + len(label) + 1
+	}
+	builder.WriteString(strings.TrimRight(string(monthLine), " ") + "\n")
+
+	for weekday, rowLabel := range rowLabels {
+		builder.WriteString(fmt.Sprintf("%-*s", labelWidth, rowLabel))
+		for _, weekCounts := range heatmap.Counts {
+			count := weekCounts[weekday]
+			if count < 0 {
+				break
+			}
+			builder.WriteString(renderHeatmapCell(heatmap.Level(count), color) + " ")
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(strings.Repeat(" ", labelWidth) + "Less ")
+	for level := 0; level < HeatmapLevels; level++ {
+		builder.WriteString(renderHeatmapCell(level, color) + " ")
+	}
+	builder.WriteString("More\n")
+	return builder.String()
+}
+
+func renderHeatmapCell(level int, color bool) string {
+	if !color {
+		return string(heatmapRunes[level])
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm■\x1b[0m", heatmapColors[level])
+}
@@ -0,0 +1,60 @@
+package views
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeatmap(t *testing.T) {
+	// a Wednesday
+	end := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	day := func(month time.Month, day int) int64 {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC).Unix()
+	}
+	dailyCounts := map[int64]int{
+		day(5, 5):  1,
+		day(5, 13): 8,
+		day(5, 15): 3,
+		// before the start of the heatmap
+		day(4, 1): 100,
+	}
+	heatmap := NewHeatmap(dailyCounts, end, 2, 0)
+
+	t.Run("NewHeatmap", func(t *testing.T) {
+		expectedStart := time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)
+		if !heatmap.Start.Equal(expectedStart) {
+			t.Errorf("got start %s but expected %s", heatmap.Start, expectedStart)
+		}
+		expectedCounts := [][]int{
+			{1, 0, 0, 0, 0, 0, 0},
+			{0, 8, 0, 3, -1, -1, -1},
+		}
+		for week := range expectedCounts {
+			for weekday := range expectedCounts[week] {
+				if count := heatmap.Counts[week][weekday]; count != expectedCounts[week][weekday] {
+					t.Errorf("got count %d for week %d day %d but expected %d", count, week, weekday, expectedCounts[week][weekday])
+				}
+			}
+		}
+		if heatmap.Max != 8 {
+			t.Errorf("got max %d but expected 8", heatmap.Max)
+		}
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		testCases := []struct {
+			Count    int
+			Expected int
+		}{
+			{0, 0},
+			{1, 1},
+			{3, 2},
+			{8, 4},
+		}
+		for _, testCase := range testCases {
+			if level := heatmap.Level(testCase.Count); level != testCase.Expected {
+				t.Errorf("got level %d for count %d but expected %d", level, testCase.Count, testCase.Expected)
+			}
+		}
+	})
+}
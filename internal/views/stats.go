@@ -0,0 +1,281 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/adamkpickering/clsr/internal/stats"
+	"github.com/adamkpickering/clsr/internal/utils"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// The number of days shown in the forecast tab.
+	statsForecastDays = 30
+	// The number of weeks shown in the retention tab.
+	statsRetentionWeeks = 12
+	// The number of weeks shown in the activity tab.
+	statsActivityWeeks = 26
+	// The width in characters of the longest bar in a bar chart.
+	statsBarWidth = 40
+)
+
+// A single character on the screen and the style it is drawn with.
+type cell struct {
+	Rune  rune
+	Style tcell.Style
+}
+
+type line []cell
+
+func newLine(s string) line {
+	newLine := make(line, 0, len(s))
+	for _, r := range s {
+		newLine = append(newLine, cell{Rune: r, Style: StyleDefault})
+	}
+	return newLine
+}
+
+type statsTab struct {
+	Name  string
+	Lines []line
+}
+
+// A dashboard of statistics about the cards in Decks, with a tab for
+// each kind of statistic.
+type StatsView struct {
+	Screen    tcell.Screen
+	Decks     []*models.Deck
+	Scheduler scheduler.Scheduler
+	Config    *config.Config
+	Clock     clock.Clock
+}
+
+// Runs the dashboard until the user quits.
+func (sv StatsView) Run() error {
+	tabs, err := sv.getTabs()
+	if err != nil {
+		return err
+	}
+	selected := 0
+	for {
+		sv.Screen.Clear()
+		sv.render(tabs, selected)
+		sv.Screen.Show()
+
+		eventInterface := sv.Screen.PollEvent()
+		switch event := eventInterface.(type) {
+		case *tcell.EventResize:
+			sv.Screen.Sync()
+		case *tcell.EventKey:
+			key := event.Key()
+			var keyRune rune
+			if key == tcell.KeyRune {
+				keyRune = event.Rune()
+			}
+			switch {
+			case key == tcell.KeyEscape || key == tcell.KeyCtrlC || keyRune == 'q':
+				return nil
+			case key == tcell.KeyRight || key == tcell.KeyTab || keyRune == 'l':
+				selected = (selected + 1) % len(tabs)
+			case key == tcell.KeyLeft || key == tcell.KeyBacktab || keyRune == 'h':
+				selected = (selected + len(tabs) - 1) % len(tabs)
+			case keyRune >= '1' && keyRune < '1'+rune(len(tabs)):
+				selected = int(keyRune - '1')
+			}
+		}
+	}
+}
+
+// Computes the contents of every tab, so that switching between tabs
+// is instant.
+func (sv StatsView) getTabs() ([]statsTab, error) {
+	cards := []*models.Card{}
+	for _, deck := range sv.Decks {
+		cards = append(cards, deck.Cards...)
+	}
+	forecast, err := sv.getForecastLines(cards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast: %w", err)
+	}
+	intervals, err := sv.getIntervalLines(cards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interval distribution: %w", err)
+	}
+	decks, err := sv.getDeckLines()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck stats: %w", err)
+	}
+	return []statsTab{
+		{Name: "Forecast", Lines: forecast},
+		{Name: "Retention", Lines: sv.getRetentionLines(cards)},
+		{Name: "Intervals", Lines: intervals},
+		{Name: "Decks", Lines: decks},
+		{Name: "Activity", Lines: sv.getActivityLines(cards)},
+	}, nil
+}
+
+func (sv StatsView) getForecastLines(cards []*models.Card) ([]line, error) {
+	now := sv.Clock.Now()
+	forecast, err := stats.GetDueForecast(cards, sv.Scheduler, now, sv.Config.DayStartHour, statsForecastDays)
+	if err != nil {
+		return nil, err
+	}
+	today := utils.StartOfDay(now, sv.Config.DayStartHour)
+	labels := make([]string, 0, len(forecast))
+	for i := range forecast {
+		labels = append(labels, today.AddDate(0, 0, i).Format("Mon Jan 02"))
+	}
+	lines := []line{newLine(fmt.Sprintf("Cards due on each of the next %d days", statsForecastDays)), newLine("")}
+	return append(lines, getBarChartLines(labels, forecast)...), nil
+}
+
+func (sv StatsView) getRetentionLines(cards []*models.Card) []line {
+	periods := stats.GetWeeklyRetention(cards, sv.Clock.Now(), sv.Config.DayStartHour, statsRetentionWeeks)
+	lines := []line{
+		newLine("Fraction of reviews that were not failed, by week"),
+		newLine(""),
+		newLine(fmt.Sprintf("%-12s%-22s%s", "Week of", "All reviews", "Mature reviews")),
+	}
+	for _, period := range periods {
+		all := fmt.Sprintf("%s (%d)", formatPercentage(period.ReviewPassed, period.ReviewCount), period.ReviewCount)
+		mature := fmt.Sprintf("%s (%d)", formatPercentage(period.MatureReviewPassed, period.MatureReviewCount), period.MatureReviewCount)
+		lines = append(lines, newLine(fmt.Sprintf("%-12s%-22s%s", period.Start.Format("2006-01-02"), all, mature)))
+	}
+	return lines
+}
+
+func (sv StatsView) getIntervalLines(cards []*models.Card) ([]line, error) {
+	buckets, err := stats.GetIntervalDistribution(cards, sv.Scheduler)
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(buckets))
+	counts := make([]int, 0, len(buckets))
+	for _, bucket := range buckets {
+		labels = append(labels, bucket.Label)
+		counts = append(counts, bucket.Count)
+	}
+	lines := []line{newLine("Active cards by time between last and next review"), newLine("")}
+	return append(lines, getBarChartLines(labels, counts)...), nil
+}
+
+func (sv StatsView) getDeckLines() ([]line, error) {
+	format := "%-20s%8s%10s%12s%12s%10s%12s"
+	lines := []line{newLine(fmt.Sprintf(format, "Deck", "Cards", "Reviews", "Per Day", "Retention", "Streak", "Interval"))}
+	for _, deck := range sv.Decks {
+		deckStats, err := stats.Compute(deck.Cards, sv.Scheduler, sv.Clock.Now(), sv.Config.DayStartHour)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute stats for deck %q: %w", deck.Name, err)
+		}
+		lines = append(lines, newLine(fmt.Sprintf(format,
+			deck.Name,
+			fmt.Sprint(deckStats.CardCount),
+			fmt.Sprint(deckStats.ReviewCount),
+			fmt.Sprintf("%.1f", deckStats.ReviewsPerDay),
+			formatPercentage(deckStats.MatureReviewPassed, deckStats.MatureReviewCount),
+			fmt.Sprintf("%dd", deckStats.CurrentStreak),
+			fmt.Sprintf("%.1fd", deckStats.AverageInterval.Hours()/24),
+		)))
+	}
+	return lines, nil
+}
+
+func (sv StatsView) getActivityLines(cards []*models.Card) []line {
+	dailyCounts := stats.GetDailyReviewCounts(cards, sv.Config.DayStartHour)
+	heatmap := NewHeatmap(dailyCounts, sv.Clock.Now(), statsActivityWeeks, sv.Config.DayStartHour)
+	rendered := strings.Split(strings.TrimSuffix(heatmap.Render(false), "\n"), "\n")
+	lines := make([]line, 0, len(rendered)+2)
+	for _, renderedLine := range rendered {
+		lines = append(lines, newLine(renderedLine))
+	}
+
+	// colour the cells of the heatmap, which are drawn using
+	// a different rune for each level
+	for _, l := range lines {
+		for i := range l {
+			for level, r := range heatmapRunes {
+				if l[i].Rune == r {
+					l[i].Rune = '■'
+					l[i].Style = StyleDefault.Foreground(tcell.PaletteColor(heatmapColors[level]))
+				}
+			}
+		}
+	}
+
+	currentStreak, longestStreak := stats.GetStreaks(dailyCounts, sv.Clock.Now(), sv.Config.DayStartHour)
+	lines = append(lines, newLine(""))
+	lines = append(lines, newLine(fmt.Sprintf("Current streak: %d days. Longest streak: %d days.", currentStreak, longestStreak)))
+	return lines
+}
+
+func (sv StatsView) render(tabs []statsTab, selected int) {
+	// add the tab bar
+	tabBar := line{cell{Rune: ' ', Style: StyleDefault}}
+	for i, tab := range tabs {
+		style := StyleDefault
+		if i == selected {
+			style = style.Reverse(true)
+		}
+		for _, r := range fmt.Sprintf(" %d %s ", i+1, tab.Name) {
+			tabBar = append(tabBar, cell{Rune: r, Style: style})
+		}
+		tabBar = append(tabBar, cell{Rune: ' ', Style: StyleDefault})
+	}
+	lines := []line{tabBar, newLine("")}
+
+	for _, tabLine := range tabs[selected].Lines {
+		lines = append(lines, append(newLine(" "), tabLine...))
+	}
+
+	// draw as many lines as fit, keeping the controls line at the bottom
+	_, height := sv.Screen.Size()
+	controls := newLine(" <left>/<right>/<1-5>: switch tab    <ctrl-C>/<escape>/<q>: exit")
+	if len(lines) > height-1 {
+		lines = lines[:max(height-1, 0)]
+	}
+	for lineIndex, l := range lines {
+		for i, c := range l {
+			sv.Screen.SetContent(i, lineIndex, c.Rune, nil, c.Style)
+		}
+	}
+	for i, c := range controls {
+		sv.Screen.SetContent(i, height-1, c.Rune, nil, c.Style)
+	}
+}
+
+// Returns a horizontal bar chart with a bar for each count.
+func getBarChartLines(labels []string, counts []int) []line {
+	labelWidth := 0
+	maxCount := 0
+	for i := range labels {
+		labelWidth = max(labelWidth, len(labels[i]))
+		maxCount = max(maxCount, counts[i])
+	}
+	lines := make([]line, 0, len(labels))
+	for i := range labels {
+		barLength := 0
+		if maxCount > 0 {
+			barLength = counts[i] * statsBarWidth / maxCount
+		}
+		if barLength == 0 && counts[i] > 0 {
+			barLength = 1
+		}
+		text := fmt.Sprintf("%-*s │%s %d", labelWidth, labels[i], strings.Repeat("█", barLength), counts[i])
+		lines = append(lines, newLine(text))
+	}
+	return lines
+}
+
+// Returns numerator/denominator as a percentage, or "n/a" if
+// denominator is 0.
+func formatPercentage(numerator, denominator int) string {
+	if denominator == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(numerator)/float64(denominator))
+}
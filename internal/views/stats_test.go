@@ -0,0 +1,85 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Returns the text on the given row of screen.
+func getScreenRow(screen tcell.SimulationScreen, row int) string {
+	cells, width, _ := screen.GetContents()
+	var builder strings.Builder
+	for _, cell := range cells[row*width : (row+1)*width] {
+		builder.WriteString(string(cell.Runes))
+	}
+	return builder.String()
+}
+
+func TestStatsView(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to initialize screen: %s", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(120, 40)
+
+	now := time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC)
+	fixedClock := clock.NewFixedClock(now)
+	deck := models.NewDeck("test_deck", true)
+	card := models.NewCard("question", "answer", deck.Name)
+	card.Reviews = models.ReviewSlice{models.NewReview(models.Normal, now.Add(-time.Hour))}
+	deck.Cards = []*models.Card{card}
+	sv := StatsView{
+		Screen:    screen,
+		Decks:     []*models.Deck{deck},
+		Scheduler: scheduler.NewTwoReviewScheduler(config.DefaultConfig, fixedClock),
+		Config:    config.DefaultConfig,
+		Clock:     fixedClock,
+	}
+
+	// switch to the decks tab, then quit
+	screen.InjectKey(tcell.KeyRune, '4', tcell.ModNone)
+	screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+	if err := sv.Run(); err != nil {
+		t.Fatalf("failed to run stats view: %s", err)
+	}
+	if row := getScreenRow(screen, 0); !strings.Contains(row, "4 Decks") {
+		t.Errorf("tab bar %q does not contain the decks tab", row)
+	}
+	if row := getScreenRow(screen, 3); !strings.Contains(row, "test_deck") {
+		t.Errorf("row %q does not contain the test deck", row)
+	}
+}
+
+func TestStatsViewForecastLabels(t *testing.T) {
+	// before the start of the day, so it is still May 13
+	now := time.Date(2024, 5, 14, 2, 0, 0, 0, time.UTC)
+	fixedClock := clock.NewFixedClock(now)
+	cfg := config.DefaultConfig.Copy()
+	cfg.DayStartHour = 4
+	card := models.NewCard("question", "answer", "test_deck")
+	sv := StatsView{
+		Scheduler: scheduler.NewTwoReviewScheduler(cfg, fixedClock),
+		Config:    cfg,
+		Clock:     fixedClock,
+	}
+
+	lines, err := sv.getForecastLines([]*models.Card{card})
+	if err != nil {
+		t.Fatalf("failed to get forecast lines: %s", err)
+	}
+	var builder strings.Builder
+	for _, cell := range lines[2] {
+		builder.WriteRune(cell.Rune)
+	}
+	if row := builder.String(); !strings.HasPrefix(row, "Mon May 13") {
+		t.Errorf("first forecast row %q is not labelled %q", row, "Mon May 13")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
 	"github.com/adamkpickering/clsr/internal/models"
 	"github.com/adamkpickering/clsr/internal/scheduler"
 	"github.com/adamkpickering/clsr/internal/utils"
@@ -22,6 +24,9 @@ type studyState int
 const (
 	questionState studyState = iota
 	questionAndAnswerState
+	// The card has been reviewed, and a notice about the review is
+	// shown until the user moves on.
+	noticeState
 )
 
 const (
@@ -42,28 +47,215 @@ type StudySession struct {
 	Screen    tcell.Screen
 	Cards     []*models.Card
 	Scheduler scheduler.Scheduler
+	Config    *config.Config
+	Clock     clock.Clock
 }
 
-// Runs a study session. If err is ErrExit, the user requested to quit
+// Runs a study session. Cards that are due are studied in the order
+// they were passed in. Cards that are not due yet, such as cards in a
+// learning step, are studied as soon as they come due; this includes
+// cards that come due again later today after being studied. Once no
+// other cards are left, cards that come due within Config.LearnAheadLimit
+// are studied early. Cards that come due after that are not studied,
+// so they are not counted in the total number of cards shown. If err is ErrExit, the user requested to quit
 // the study session and save changes to cards. If err is ErrEdit, the
 // user wants to edit the card whose ID is included in the first argument.
 func (ss StudySession) Run() (string, error) {
-	totalCards := len(ss.Cards)
-	for i, card := range ss.Cards {
-		if cardID, err := ss.studyCard(card, totalCards, i+1); err != nil {
+	queue := make([]*models.Card, 0, len(ss.Cards))
+	pending := []*models.Card{}
+	for _, card := range ss.Cards {
+		isDue, err := ss.Scheduler.IsDue(card)
+		if err != nil {
+			return "", fmt.Errorf("failed to check whether card %q is due: %w", card.ID, err)
+		}
+		if isDue {
+			queue = append(queue, card)
+		} else {
+			pending = append(pending, card)
+		}
+	}
+
+	cardNumber := 0
+	for {
+		card, err := ss.popNextCard(&queue, &pending)
+		if err != nil {
+			return "", err
+		}
+		if card == nil {
+			return "", nil
+		}
+		cardNumber += 1
+		learnAheadCount, err := ss.countLearnAheadCards(pending)
+		if err != nil {
+			return "", err
+		}
+		totalCards := cardNumber + len(queue) + learnAheadCount
+		cardID, reviewed, err := ss.studyCard(card, totalCards, cardNumber)
+		if err != nil {
 			return cardID, err
 		}
+		if reviewed {
+			if notice := ss.handleLeech(card); notice != "" {
+				if err := ss.showNotice(card, totalCards, cardNumber, notice); err != nil {
+					return "", err
+				}
+			}
+		}
+		dueToday, err := IsDueToday(card, ss.Scheduler, ss.Config, ss.Clock)
+		if err != nil {
+			return "", err
+		}
+		if card.Active && dueToday {
+			pending = append(pending, card)
+		}
+	}
+}
+
+// Removes and returns the card that should be studied next from queue
+// or pending. Returns nil if there are no cards left to study.
+func (ss StudySession) popNextCard(queue, pending *[]*models.Card) (*models.Card, error) {
+	// find the pending card with the earliest next review
+	earliestIndex := -1
+	var earliestNextReview time.Time
+	for i, card := range *pending {
+		nextReview, err := ss.Scheduler.GetNextReview(card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+		}
+		if earliestIndex < 0 || nextReview.Before(earliestNextReview) {
+			earliestIndex = i
+			earliestNextReview = nextReview
+		}
+	}
+
+	popPending := func() *models.Card {
+		card := (*pending)[earliestIndex]
+		*pending = append((*pending)[:earliestIndex], (*pending)[earliestIndex+1:]...)
+		return card
+	}
+
+	if earliestIndex >= 0 {
+		isDue, err := ss.Scheduler.IsDue((*pending)[earliestIndex])
+		if err != nil {
+			return nil, fmt.Errorf("failed to check whether card is due: %w", err)
+		}
+		if isDue {
+			return popPending(), nil
+		}
+	}
+	if len(*queue) > 0 {
+		card := (*queue)[0]
+		*queue = (*queue)[1:]
+		return card, nil
 	}
-	return "", nil
+	if earliestIndex >= 0 && !earliestNextReview.After(ss.Clock.Now().Add(ss.Config.LearnAheadLimit)) {
+		return popPending(), nil
+	}
+	return nil, nil
 }
 
-func (ss StudySession) studyCard(card *models.Card, totalCards, cardNumber int) (string, error) {
+// Returns the number of cards in pending that come due within
+// Config.LearnAheadLimit, and so will be studied before the session
+// ends.
+func (ss StudySession) countLearnAheadCards(pending []*models.Card) (int, error) {
+	learnAheadLimit := ss.Clock.Now().Add(ss.Config.LearnAheadLimit)
+	count := 0
+	for _, card := range pending {
+		nextReview, err := ss.Scheduler.GetNextReview(card)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+		}
+		if !nextReview.After(learnAheadLimit) {
+			count += 1
+		}
+	}
+	return count, nil
+}
+
+// Tells the caller whether card is due now, or will come due before
+// the end of the current day.
+func IsDueToday(card *models.Card, scheduler scheduler.Scheduler, config *config.Config, clock clock.Clock) (bool, error) {
+	isDue, err := scheduler.IsDue(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether card %q is due: %w", card.ID, err)
+	}
+	if isDue {
+		return true, nil
+	}
+	nextReview, err := scheduler.GetNextReview(card)
+	if err != nil {
+		return false, fmt.Errorf("failed to get next review for card %q: %w", card.ID, err)
+	}
+	endOfToday := utils.StartOfDay(clock.Now(), config.DayStartHour).AddDate(0, 0, 1)
+	return nextReview.Before(endOfToday), nil
+}
+
+// Checks whether the last review of card made it a leech, and if so
+// handles it according to Config.Leech. Returns a notice for the user,
+// or an empty string if card did not become a leech. A card only
+// becomes a leech on the failed review that brings its lapses up to
+// the threshold, so later failed reviews do not repeat the notice.
+func (ss StudySession) handleLeech(card *models.Card) string {
+	if len(card.Reviews) == 0 || card.Reviews[0].Result != models.Failed {
+		return ""
+	}
+	lapses := card.LapseCount()
+	if !card.IsLeech(ss.Config.Leech.Threshold) || lapses != int(ss.Config.Leech.Threshold) {
+		return ""
+	}
+	switch ss.Config.Leech.Action {
+	case config.LeechActionTag:
+		card.AddTag(models.LeechTag)
+		return fmt.Sprintf("Card %s has been failed %d times and was tagged as a leech", card.ID, lapses)
+	case config.LeechActionSuspend:
+		card.AddTag(models.LeechTag)
+		card.Active = false
+		card.Modified = true
+		return fmt.Sprintf("Card %s has been failed %d times and was set to inactive", card.ID, lapses)
+	default:
+		return fmt.Sprintf("Card %s has been failed %d times and is a leech", card.ID, lapses)
+	}
+}
+
+// Shows notice along with card, which has just been reviewed, until
+// the user moves on. Returns ErrExit if the user chose to quit.
+func (ss StudySession) showNotice(card *models.Card, totalCards, cardNumber int, notice string) error {
+	for {
+		ss.Screen.Clear()
+		if err := ss.render(card, noticeState, totalCards, cardNumber, notice); err != nil {
+			return err
+		}
+		ss.Screen.Show()
+
+		switch event := ss.Screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			ss.Screen.Sync()
+		case *tcell.EventKey:
+			key := event.Key()
+			var keyRune rune
+			if key == tcell.KeyRune {
+				keyRune = event.Rune()
+			}
+			if key == tcell.KeyEscape || key == tcell.KeyCtrlC || keyRune == 'q' {
+				return ErrExit
+			}
+			if key == tcell.KeyEnter || keyRune == ' ' {
+				return nil
+			}
+		}
+	}
+}
+
+// Studies a single card. The returned bool tells the caller whether
+// a review was added to card, which is not the case if the user set
+// it to inactive instead.
+func (ss StudySession) studyCard(card *models.Card, totalCards, cardNumber int) (string, bool, error) {
 	state := questionState
 	for {
 		// render screen
 		ss.Screen.Clear()
-		if err := ss.render(card, state, totalCards, cardNumber, ss.Scheduler); err != nil {
-			return "", err
+		if err := ss.render(card, state, totalCards, cardNumber, ""); err != nil {
+			return "", false, err
 		}
 		ss.Screen.Show()
 
@@ -81,7 +273,7 @@ func (ss StudySession) studyCard(card *models.Card, totalCards, cardNumber int)
 
 			// allow user to exit cleanly and prematurely
 			if key == tcell.KeyEscape || key == tcell.KeyCtrlC || keyRune == 'q' {
-				return "", ErrExit
+				return "", false, ErrExit
 			}
 
 			// handle different keys depending on different states
@@ -90,9 +282,9 @@ func (ss StudySession) studyCard(card *models.Card, totalCards, cardNumber int)
 				if keyRune == 'i' {
 					card.Active = false
 					card.Modified = true
-					return "", nil
+					return "", false, nil
 				} else if keyRune == 'e' {
-					return card.ID, ErrEdit
+					return card.ID, false, ErrEdit
 				} else if key == tcell.KeyEnter || keyRune == ' ' {
 					state = questionAndAnswerState
 				}
@@ -100,18 +292,18 @@ func (ss StudySession) studyCard(card *models.Card, totalCards, cardNumber int)
 				if keyRune == 'i' {
 					card.Active = false
 					card.Modified = true
-					return "", nil
+					return "", false, nil
 				} else if keyRune == 'e' {
-					return card.ID, ErrEdit
+					return card.ID, false, ErrEdit
 				}
 				reviewResult, ok := keyToReviewResult[keyRune]
 				if !ok {
 					continue
 				}
-				newReview := models.NewReview(reviewResult)
+				newReview := models.NewReview(reviewResult, ss.Clock.Now())
 				card.Reviews = append(models.ReviewSlice{newReview}, card.Reviews...)
 				card.Modified = true
-				return "", nil
+				return "", true, nil
 			}
 		}
 	}
@@ -123,14 +315,18 @@ func (ss StudySession) processString(rawString string) []string {
 	return stringLines
 }
 
-func (ss StudySession) render(card *models.Card, state studyState, totalCards, cardNumber int, scheduler scheduler.Scheduler) error {
+func (ss StudySession) render(card *models.Card, state studyState, totalCards, cardNumber int, notice string) error {
 	var lines []string
 
-	// add the status line
+	// add the status line and (maybe) a notice
 	statusFmtString := " Card %d/%d\t\t\tDeck: %s\t\t\tID: %s"
 	statusLine := fmt.Sprintf(statusFmtString, cardNumber, totalCards, card.Deck, card.ID)
 	lines = append(lines, statusLine)
-	lines = append(lines, "")
+	if notice == "" {
+		lines = append(lines, "")
+	} else {
+		lines = append(lines, " "+notice)
+	}
 
 	// add question, divider and (maybe) answer
 	for _, questionLine := range ss.processString(card.Question) {
@@ -144,7 +340,7 @@ func (ss StudySession) render(card *models.Card, state studyState, totalCards, c
 		for i := 0; i < len(ss.processString(card.Answer)); i++ {
 			lines = append(lines, "")
 		}
-	case questionAndAnswerState:
+	case questionAndAnswerState, noticeState:
 		for _, answerLine := range ss.processString(card.Answer) {
 			lines = append(lines, " "+answerLine)
 		}
@@ -157,19 +353,19 @@ func (ss StudySession) render(card *models.Card, state studyState, totalCards, c
 	case questionState:
 		lines = append(lines, " <space>/<enter>: show answer")
 	case questionAndAnswerState:
-		failed, err := getReadableDurationForResult(models.Failed, card, scheduler)
+		failed, err := ss.getReadableDurationForResult(models.Failed, card)
 		if err != nil {
 			return fmt.Errorf("failed to get readable duration for Failed: %w", err)
 		}
-		hard, err := getReadableDurationForResult(models.Hard, card, scheduler)
+		hard, err := ss.getReadableDurationForResult(models.Hard, card)
 		if err != nil {
 			return fmt.Errorf("failed to get readable duration for Hard: %w", err)
 		}
-		normal, err := getReadableDurationForResult(models.Normal, card, scheduler)
+		normal, err := ss.getReadableDurationForResult(models.Normal, card)
 		if err != nil {
 			return fmt.Errorf("failed to get readable duration for Normal: %w", err)
 		}
-		easy, err := getReadableDurationForResult(models.Easy, card, scheduler)
+		easy, err := ss.getReadableDurationForResult(models.Easy, card)
 		if err != nil {
 			return fmt.Errorf("failed to get readable duration for Easy: %w", err)
 		}
@@ -183,9 +379,13 @@ func (ss StudySession) render(card *models.Card, state studyState, totalCards, c
 			easyKey, easy,
 		)
 		lines = append(lines, keyLine)
+	case noticeState:
+		lines = append(lines, " <space>/<enter>: continue")
+	}
+	if state != noticeState {
+		lines = append(lines, " <e>: edit card")
+		lines = append(lines, " <i>: set card to inactive")
 	}
-	lines = append(lines, " <e>: edit card")
-	lines = append(lines, " <i>: set card to inactive")
 	lines = append(lines, " <ctrl-C>/<escape>/<q>: save studied cards & exit")
 
 	// print to screen
@@ -200,17 +400,17 @@ func (ss StudySession) render(card *models.Card, state studyState, totalCards, c
 	return nil
 }
 
-func getReadableDurationForResult(result models.ReviewResult, card *models.Card, scheduler scheduler.Scheduler) (string, error) {
-	nextReview, err := getHypotheticalNextReview(result, card, scheduler)
+func (ss StudySession) getReadableDurationForResult(result models.ReviewResult, card *models.Card) (string, error) {
+	nextReview, err := ss.getHypotheticalNextReview(result, card)
 	if err != nil {
 		return "", fmt.Errorf("failed to get hypothetical next review: %w", err)
 	}
-	return utils.GetReadableTimeDifference(time.Now(), nextReview), nil
+	return utils.GetReadableTimeDifference(ss.Clock.Now(), nextReview, ss.Config.DayStartHour), nil
 }
 
-func getHypotheticalNextReview(result models.ReviewResult, card *models.Card, scheduler scheduler.Scheduler) (time.Time, error) {
+func (ss StudySession) getHypotheticalNextReview(result models.ReviewResult, card *models.Card) (time.Time, error) {
 	newCard := card.Copy()
-	newReview := models.NewReview(result)
+	newReview := models.NewReview(result, ss.Clock.Now())
 	newCard.Reviews = append(models.ReviewSlice{newReview}, newCard.Reviews...)
-	return scheduler.GetNextReview(newCard)
+	return ss.Scheduler.GetNextReview(newCard)
 }
@@ -0,0 +1,135 @@
+package views
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkpickering/clsr/internal/clock"
+	"github.com/adamkpickering/clsr/internal/config"
+	"github.com/adamkpickering/clsr/internal/models"
+	"github.com/adamkpickering/clsr/internal/scheduler"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestStudySession(t *testing.T) {
+	now := time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC)
+	fixedClock := clock.NewFixedClock(now)
+	leechConfig := config.DefaultConfig.Copy()
+	leechConfig.Leech = config.LeechConfig{Threshold: 2, Action: config.LeechActionTag}
+	newCardWithLapses := func(lapses int) *models.Card {
+		card := models.NewCard("question", "answer", "test_deck")
+		for i := 0; i < lapses; i++ {
+			review := models.NewReview(models.Failed, now.AddDate(0, 0, -10*(i+1)))
+			card.Reviews = append(card.Reviews, review)
+		}
+		return card
+	}
+
+	t.Run("ShowsLeechNoticeOnLastCard", func(t *testing.T) {
+		screen := tcell.NewSimulationScreen("")
+		if err := screen.Init(); err != nil {
+			t.Fatalf("failed to initialize screen: %s", err)
+		}
+		defer screen.Fini()
+		screen.SetSize(120, 40)
+
+		card := newCardWithLapses(1)
+		ss := StudySession{
+			Screen:    screen,
+			Cards:     []*models.Card{card},
+			Scheduler: scheduler.NewTwoReviewScheduler(leechConfig, fixedClock),
+			Config:    leechConfig,
+			Clock:     fixedClock,
+		}
+
+		// show the answer, fail the card, then quit from the notice
+		screen.InjectKey(tcell.KeyRune, ' ', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, failedKey, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+		if _, err := ss.Run(); !errors.Is(err, ErrExit) {
+			t.Fatalf("got error %v but expected ErrExit", err)
+		}
+		if row := getScreenRow(screen, 1); !strings.Contains(row, "tagged as a leech") {
+			t.Errorf("notice row %q does not contain the leech notice", row)
+		}
+		if !card.HasTag(models.LeechTag) {
+			t.Errorf("expected card to be tagged as a leech")
+		}
+	})
+
+	t.Run("NoLeechNoticeWhenSetInactive", func(t *testing.T) {
+		screen := tcell.NewSimulationScreen("")
+		if err := screen.Init(); err != nil {
+			t.Fatalf("failed to initialize screen: %s", err)
+		}
+		defer screen.Fini()
+		screen.SetSize(120, 40)
+
+		// the last review of the card made it a leech in an earlier session
+		card := newCardWithLapses(2)
+		ss := StudySession{
+			Screen:    screen,
+			Cards:     []*models.Card{card},
+			Scheduler: scheduler.NewTwoReviewScheduler(leechConfig, fixedClock),
+			Config:    leechConfig,
+			Clock:     fixedClock,
+		}
+
+		// quit from a leech notice if one is wrongly shown
+		screen.InjectKey(tcell.KeyRune, 'i', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+		if _, err := ss.Run(); err != nil {
+			t.Fatalf("failed to run study session: %s", err)
+		}
+		if card.Active {
+			t.Errorf("expected card to be inactive")
+		}
+		if card.HasTag(models.LeechTag) {
+			t.Errorf("expected card that was not reviewed not to be tagged as a leech")
+		}
+	})
+
+	t.Run("HandleLeechOnlyOnce", func(t *testing.T) {
+		ss := StudySession{Config: leechConfig}
+		card := newCardWithLapses(2)
+		if notice := ss.handleLeech(card); notice == "" {
+			t.Errorf("expected a notice when the card reaches the threshold")
+		}
+		card.Reviews = append(models.ReviewSlice{models.NewReview(models.Failed, now)}, card.Reviews...)
+		if notice := ss.handleLeech(card); notice != "" {
+			t.Errorf("got notice %q for a card that was already a leech", notice)
+		}
+	})
+
+	t.Run("TotalLeavesOutCardsDueLaterToday", func(t *testing.T) {
+		screen := tcell.NewSimulationScreen("")
+		if err := screen.Init(); err != nil {
+			t.Fatalf("failed to initialize screen: %s", err)
+		}
+		defer screen.Fini()
+		screen.SetSize(120, 40)
+
+		cfg := config.DefaultConfig.Copy()
+		dueCard := models.NewCard("question", "answer", "test_deck")
+		// comes due later today, after Config.LearnAheadLimit
+		laterCard := models.NewCard("question", "answer", "test_deck")
+		laterCard.Reviews = models.ReviewSlice{models.NewReview(models.Failed, now.Add(-time.Hour))}
+		ss := StudySession{
+			Screen:    screen,
+			Cards:     []*models.Card{dueCard, laterCard},
+			Scheduler: scheduler.NewTwoReviewScheduler(cfg, fixedClock),
+			Config:    cfg,
+			Clock:     fixedClock,
+		}
+
+		screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+		if _, err := ss.Run(); !errors.Is(err, ErrExit) {
+			t.Fatalf("got error %v but expected ErrExit", err)
+		}
+		if row := getScreenRow(screen, 0); !strings.Contains(row, "Card 1/1") {
+			t.Errorf("status row %q does not contain %q", row, "Card 1/1")
+		}
+	})
+}